@@ -0,0 +1,232 @@
+package gitgone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+// BisectFunc tests the commit that is checked out in the repository.
+// It reports whether the commit is good, or should be skipped because
+// it cannot be tested. Errors abort the bisect.
+type BisectFunc func(Repository) (good bool, skip bool, err error)
+
+// BisectVerdict is the outcome of testing a commit.
+type BisectVerdict int
+
+const (
+	BisectGood BisectVerdict = iota
+	BisectBad
+	BisectSkip
+)
+
+func (v BisectVerdict) String() string {
+	switch v {
+	case BisectGood:
+		return "good"
+	case BisectBad:
+		return "bad"
+	case BisectSkip:
+		return "skip"
+	default:
+		return fmt.Sprintf("BisectVerdict(%d)", int(v))
+	}
+}
+
+// BisectStep records the verdict for a commit.
+type BisectStep struct {
+	Commit  string
+	Verdict BisectVerdict
+}
+
+// Bisection is the outcome of a bisect. The log begins with the
+// initial good and bad commits, followed by every commit tested. When
+// only skipped commits remain, FirstBad is empty and Candidates lists
+// the commits that may be the first bad commit. State is Good when
+// the bisect found the first bad commit and restored the original
+// checkout.
+type Bisection struct {
+	FirstBad   string
+	Candidates []string
+	Log        []*BisectStep
+	State      states.RepositoryState
+}
+
+// ErrBisectSkipped is returned when the only commits left to test
+// were skipped.
+var ErrBisectSkipped = errors.New("only skipped commits are left to test")
+
+// Bisect finds the first bad commit between the good commits and the
+// bad commit, by checking out commits that divide the remaining
+// history in half and testing them. When it finishes the original
+// branch, or commit, is checked out again.
+func (self *RepositoryManager) Bisect(bad string, good []string, test BisectFunc) (*Bisection, error) {
+	result := &Bisection{State: states.Detached}
+
+	if len(good) == 0 {
+		result.State = states.FailedOperation
+		return result, fmt.Errorf("bisect requires at least one good commit")
+	}
+
+	original := self.Branch()
+	if original == "" {
+		head, err := self.ResolveCommit("HEAD")
+		if err != nil {
+			result.State = states.FailedOperation
+			return result, err
+		}
+		original = head
+	}
+
+	// resolve revisions before checking anything out, since relative
+	// revisions change meaning as HEAD moves.
+	bad, err := self.ResolveCommit(bad)
+	if err != nil {
+		result.State = states.FailedOperation
+		return result, err
+	}
+
+	var goods []string
+	for _, rev := range good {
+		commit, err := self.ResolveCommit(rev)
+		if err != nil {
+			result.State = states.FailedOperation
+			return result, err
+		}
+
+		descendants, err := self.RevList([]string{commit}, []string{bad})
+		if err != nil {
+			result.State = states.FailedOperation
+			return result, err
+		}
+		if len(descendants) > 0 {
+			result.State = states.FailedOperation
+			return result, fmt.Errorf("good commit %s is not an ancestor of bad commit %s", commit, bad)
+		}
+
+		goods = append(goods, commit)
+		result.Log = append(result.Log, &BisectStep{Commit: commit, Verdict: BisectGood})
+	}
+	result.Log = append(result.Log, &BisectStep{Commit: bad, Verdict: BisectBad})
+
+	err = self.bisect(result, bad, goods, test)
+
+	if restoreErr := self.Checkout(original); restoreErr != nil {
+		result.State = states.UnresolvedOperation
+		if err == nil {
+			err = fmt.Errorf("could not restore %s after bisect: %s", original, restoreErr)
+		}
+	}
+
+	return result, err
+}
+
+func (self *RepositoryManager) bisect(result *Bisection, bad string, goods []string, test BisectFunc) error {
+	skipped := map[string]bool{}
+
+	for {
+		commits, err := self.RevList([]string{bad}, goods)
+		if err != nil {
+			result.State = states.FailedOperation
+			return err
+		}
+
+		next := bisectMidpoint(commits, bad, skipped)
+		if next == "" {
+			var remaining []string
+			for _, commit := range commits {
+				if skipped[commit.ID] {
+					remaining = append(remaining, commit.ID)
+				}
+			}
+
+			if len(remaining) == 0 {
+				result.FirstBad = bad
+				result.State = states.Good
+				return nil
+			}
+
+			result.Candidates = append([]string{bad}, remaining...)
+			result.State = states.IncompleteOperation
+			return fmt.Errorf("%s, the first bad commit is one of: %s",
+				ErrBisectSkipped, strings.Join(result.Candidates, ", "))
+		}
+
+		if err = self.Checkout(next); err != nil {
+			result.State = states.FailedOperation
+			return err
+		}
+
+		isGood, skip, err := test(self.Repository)
+		if err != nil {
+			result.State = states.IncompleteOperation
+			return err
+		}
+
+		switch {
+		case skip:
+			skipped[next] = true
+			result.Log = append(result.Log, &BisectStep{Commit: next, Verdict: BisectSkip})
+		case isGood:
+			goods = append(goods, next)
+			result.Log = append(result.Log, &BisectStep{Commit: next, Verdict: BisectGood})
+		default:
+			bad = next
+			result.Log = append(result.Log, &BisectStep{Commit: next, Verdict: BisectBad})
+		}
+	}
+}
+
+// bisectMidpoint chooses the commit that most evenly divides the
+// remaining commits into those it can reach and those it cannot, as
+// git does. It returns an empty string when no untested commits
+// remain.
+func bisectMidpoint(commits []*operations.CommitNode, bad string, skipped map[string]bool) string {
+	parents := map[string][]string{}
+	for _, commit := range commits {
+		parents[commit.ID] = commit.Parents
+	}
+
+	best, bestScore := "", -1
+	for _, commit := range commits {
+		if commit.ID == bad || skipped[commit.ID] {
+			continue
+		}
+
+		reachable := countReachable(commit.ID, parents)
+		score := reachable
+		if len(commits)-reachable < score {
+			score = len(commits) - reachable
+		}
+
+		if score > bestScore {
+			best, bestScore = commit.ID, score
+		}
+	}
+
+	return best
+}
+
+// countReachable returns the number of commits, among those in the
+// graph, that are reachable from the commit, including itself.
+func countReachable(start string, parents map[string][]string) int {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+
+	for len(queue) > 0 {
+		commit := queue[0]
+		queue = queue[1:]
+
+		for _, parent := range parents[commit] {
+			if _, ok := parents[parent]; ok && !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return len(seen)
+}
@@ -0,0 +1,101 @@
+package gitgone
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/tychoish/gitgone/states"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type BisectSuite struct {
+	repo    *RepositoryManager
+	commits []string
+}
+
+var _ = Suite(&BisectSuite{})
+
+// SetUpSuite creates a history of ten commits, where each commit
+// writes its number to a file, and the seventh introduces the bug.
+func (s *BisectSuite) SetUpSuite(c *C) {
+	s.repo = newTestRepository(c, false)
+
+	for i := 0; i < 10; i++ {
+		c.Assert(ioutil.WriteFile(filepath.Join(s.repo.Path(), "VERSION"), []byte(strconv.Itoa(i)), 0644), IsNil)
+		c.Assert(s.repo.Stage("VERSION"), IsNil)
+		c.Assert(s.repo.Commit("commit "+strconv.Itoa(i)), IsNil)
+
+		commit, err := s.repo.ResolveCommit("HEAD")
+		c.Assert(err, IsNil)
+		s.commits = append(s.commits, commit)
+	}
+}
+
+func version(repo Repository) int {
+	data, _ := ioutil.ReadFile(filepath.Join(repo.Path(), "VERSION"))
+	number, _ := strconv.Atoi(string(data))
+	return number
+}
+
+func (s *BisectSuite) TestBisect(c *C) {
+	tested := 0
+	result, err := s.repo.Bisect("HEAD", []string{"HEAD~9"}, func(repo Repository) (bool, bool, error) {
+		tested++
+		number := version(repo)
+		return number < 7, number == 3, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.State, Equals, states.Good)
+	c.Assert(result.FirstBad, Equals, s.commits[7])
+	c.Assert(result.Log[0].Commit, Equals, s.commits[0])
+	c.Assert(result.Log[0].Verdict, Equals, BisectGood)
+	c.Assert(result.Log[1].Commit, Equals, s.commits[9])
+	c.Assert(result.Log[1].Verdict, Equals, BisectBad)
+	c.Assert(result.Log, HasLen, tested+2)
+	c.Assert(tested <= 5, Equals, true)
+
+	c.Assert(s.repo.Branch(), Equals, "master")
+	c.Assert(version(s.repo), Equals, 9)
+}
+
+func (s *BisectSuite) TestBisectSkipped(c *C) {
+	result, err := s.repo.Bisect(s.commits[8], []string{s.commits[5]}, func(repo Repository) (bool, bool, error) {
+		return false, true, nil
+	})
+	c.Assert(err, NotNil)
+	c.Assert(result.State, Equals, states.IncompleteOperation)
+	c.Assert(result.FirstBad, Equals, "")
+	c.Assert(result.Candidates, HasLen, 3)
+	c.Assert(result.Candidates[0], Equals, s.commits[8])
+
+	c.Assert(s.repo.Branch(), Equals, "master")
+}
+
+func (s *BisectSuite) TestBisectDetached(c *C) {
+	git(c, s.repo, "checkout", "--quiet", "--detach", s.commits[8])
+	defer git(c, s.repo, "checkout", "--quiet", "master")
+	c.Assert(s.repo.Branch(), Equals, "")
+
+	result, err := s.repo.Bisect("HEAD", []string{s.commits[0]}, func(repo Repository) (bool, bool, error) {
+		return version(repo) < 7, false, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.FirstBad, Equals, s.commits[7])
+
+	c.Logf("the detached HEAD is restored, rather than a branch")
+	c.Assert(s.repo.Branch(), Equals, "")
+	c.Assert(git(c, s.repo, "rev-parse", "HEAD"), Equals, s.commits[8])
+	c.Assert(version(s.repo), Equals, 8)
+}
+
+func (s *BisectSuite) TestBisectInvalidRange(c *C) {
+	_, err := s.repo.Bisect(s.commits[2], []string{s.commits[4]}, nil)
+	c.Assert(err, NotNil)
+
+	_, err = s.repo.Bisect("HEAD", nil, nil)
+	c.Assert(err, NotNil)
+}
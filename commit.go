@@ -0,0 +1,96 @@
+package gitgone
+
+import (
+	"time"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// CommitBuilder creates a commit from changes held in memory, without
+// a working tree or index, so that it works in bare repositories.
+// Changes apply in the order they are added. Create a CommitBuilder
+// with RepositoryManager.NewCommitBuilder.
+type CommitBuilder struct {
+	repo    Repository
+	request operations.CommitRequest
+}
+
+// NewCommitBuilder returns a builder for a commit whose parent is the
+// given revision. An empty parent creates a root commit.
+func (self *RepositoryManager) NewCommitBuilder(parent string) *CommitBuilder {
+	return &CommitBuilder{
+		repo:    self.Repository,
+		request: operations.CommitRequest{Parent: parent},
+	}
+}
+
+func (self *CommitBuilder) addChange(change *operations.FileChange) *CommitBuilder {
+	self.request.Changes = append(self.request.Changes, change)
+	return self
+}
+
+// Write sets the contents of a file, creating any directories it
+// needs. Existing files keep their mode.
+func (self *CommitBuilder) Write(path string, contents []byte) *CommitBuilder {
+	return self.addChange(&operations.FileChange{Kind: operations.FileWrite, Path: path, Contents: contents})
+}
+
+// WriteMode sets the contents and the mode of a file.
+func (self *CommitBuilder) WriteMode(path string, contents []byte, mode operations.FileMode) *CommitBuilder {
+	return self.addChange(&operations.FileChange{Kind: operations.FileWrite, Path: path, Contents: contents, Mode: mode})
+}
+
+// Delete removes a file or directory.
+func (self *CommitBuilder) Delete(path string) *CommitBuilder {
+	return self.addChange(&operations.FileChange{Kind: operations.FileDelete, Path: path})
+}
+
+// Rename moves a file or directory.
+func (self *CommitBuilder) Rename(from, to string) *CommitBuilder {
+	return self.addChange(&operations.FileChange{Kind: operations.FileRename, Path: to, From: from})
+}
+
+// SetMode changes the mode of a file, for instance to make it
+// executable.
+func (self *CommitBuilder) SetMode(path string, mode operations.FileMode) *CommitBuilder {
+	return self.addChange(&operations.FileChange{Kind: operations.FileSetMode, Path: path, Mode: mode})
+}
+
+// Author sets the author of the commit. By default, the author is
+// the configured identity.
+func (self *CommitBuilder) Author(name, email string, when time.Time) *CommitBuilder {
+	self.request.Author = &operations.Identity{Name: name, Email: email, When: when}
+	return self
+}
+
+// Committer sets the committer of the commit. By default, the
+// committer is the configured identity.
+func (self *CommitBuilder) Committer(name, email string, when time.Time) *CommitBuilder {
+	self.request.Committer = &operations.Identity{Name: name, Email: email, When: when}
+	return self
+}
+
+// Message sets the commit message.
+func (self *CommitBuilder) Message(message string) *CommitBuilder {
+	self.request.Message = message
+	return self
+}
+
+// Expect makes Commit update the ref only if its current value is
+// the given commit id, or, when the id is empty, only if the ref does
+// not exist. Use it to avoid overwriting concurrent updates.
+func (self *CommitBuilder) Expect(old string) *CommitBuilder {
+	self.request.CompareAndSwap = true
+	self.request.Expected = old
+	return self
+}
+
+// Commit writes the commit and points the ref, such as
+// "refs/heads/master", at it, returning the id of the commit. An
+// empty ref writes the commit without updating any ref.
+func (self *CommitBuilder) Commit(ref string) (string, error) {
+	req := self.request
+	req.Ref = ref
+
+	return self.repo.WriteCommit(req)
+}
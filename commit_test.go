@@ -0,0 +1,76 @@
+package gitgone
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type CommitBuilderSuite struct {
+	repo *RepositoryManager
+}
+
+var _ = Suite(&CommitBuilderSuite{})
+
+func (s *CommitBuilderSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c, true)
+}
+
+func (s *CommitBuilderSuite) TestCommitBuilder(c *C) {
+	when := time.Unix(1500000000, 0).UTC()
+
+	first, err := s.repo.NewCommitBuilder("").
+		Write("README", []byte("readme\n")).
+		Write("conf/app/settings.yaml", []byte("debug: false\n")).
+		WriteMode("bin/run", []byte("#!/bin/sh\n"), operations.ModeExecutable).
+		Author("Alice", "alice@example.com", when).
+		Message("initial configuration").
+		Expect("").
+		Commit("refs/heads/master")
+	c.Assert(err, IsNil)
+
+	c.Assert(git(c, s.repo, "rev-parse", "master"), Equals, first)
+	c.Assert(git(c, s.repo, "show", "-s", "--format=%an %ae %at %cn %s", "master"), Equals,
+		"Alice alice@example.com 1500000000 Gitgone initial configuration")
+	c.Assert(git(c, s.repo, "ls-tree", "-r", "--name-only", "master"), Equals,
+		"README\nbin/run\nconf/app/settings.yaml")
+	c.Assert(strings.HasPrefix(git(c, s.repo, "ls-tree", "master", "bin/run"), "100755 blob"), Equals, true)
+
+	second, err := s.repo.NewCommitBuilder("master").
+		Rename("conf", "config").
+		Delete("README").
+		SetMode("bin/run", operations.ModeRegular).
+		Write("config/app/settings.yaml", []byte("debug: true\n")).
+		Message("reorganize").
+		Expect(first).
+		Commit("refs/heads/master")
+	c.Assert(err, IsNil)
+
+	c.Assert(git(c, s.repo, "rev-parse", "master^"), Equals, first)
+	c.Assert(git(c, s.repo, "ls-tree", "-r", "--name-only", "master"), Equals,
+		"bin/run\nconfig/app/settings.yaml")
+	c.Assert(git(c, s.repo, "show", "master:config/app/settings.yaml"), Equals, "debug: true")
+	c.Assert(strings.HasPrefix(git(c, s.repo, "ls-tree", "master", "bin/run"), "100644 blob"), Equals, true)
+
+	// the compare and swap fails once the ref has moved on.
+	_, err = s.repo.NewCommitBuilder("master").Delete("bin").Message("stale").Expect(first).Commit("refs/heads/master")
+	c.Assert(err, NotNil)
+	c.Assert(git(c, s.repo, "rev-parse", "master"), Equals, second)
+
+	// removing the last file in a directory removes the directory.
+	detached, err := s.repo.NewCommitBuilder(second).Delete("bin/run").Message("detached").Commit("")
+	c.Assert(err, IsNil)
+	c.Assert(git(c, s.repo, "ls-tree", "--name-only", detached), Equals, "config")
+	c.Assert(git(c, s.repo, "rev-parse", "master"), Equals, second)
+
+	_, err = s.repo.NewCommitBuilder("master").Delete("missing").Commit("")
+	c.Assert(err, NotNil)
+	_, err = s.repo.NewCommitBuilder("master").Write("../escape", nil).Commit("")
+	c.Assert(err, NotNil)
+	_, err = s.repo.NewCommitBuilder("master").Write(".git/config", nil).Commit("")
+	c.Assert(err, NotNil)
+
+	git(c, s.repo, "fsck", "--strict")
+}
@@ -0,0 +1,73 @@
+// Package config describes the configuration of a git repository, as
+// read from and written to the system, global, local and worktree
+// configuration files. Both Repository implementations provide a
+// Config that satisfies the interface in this package.
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Scope identifies one of the configuration files that git reads.
+type Scope int
+
+const (
+	// Any is only meaningful for reads, and refers to the merged
+	// view of every scope, in which later, more specific scopes
+	// override earlier ones.
+	Any Scope = iota
+	System
+	Global
+	Local
+	Worktree
+)
+
+func (s Scope) String() string {
+	switch s {
+	case Any:
+		return "any"
+	case System:
+		return "system"
+	case Global:
+		return "global"
+	case Local:
+		return "local"
+	case Worktree:
+		return "worktree"
+	default:
+		return fmt.Sprintf("Scope(%d)", int(s))
+	}
+}
+
+// ErrKeyNotFound is returned by the Get methods when the key is not
+// set in any of the scopes that were read.
+var ErrKeyNotFound = errors.New("configuration key not found")
+
+// Entry is a single key/value pair from a configuration file. Keys
+// with more than one value produce one Entry per value.
+type Entry struct {
+	Key    string
+	Value  string
+	Scope  Scope
+	Origin string
+}
+
+// Config provides typed access to a repository's configuration. Reads
+// follow the same precedence rules as git, and resolve include.path
+// and includeIf.<condition>.path directives. Writes always target
+// an explicit scope.
+type Config interface {
+	Get(string) (string, error)
+	GetAll(string) ([]string, error)
+	GetBool(string) (bool, error)
+	GetInt(string) (int64, error)
+	List(Scope) ([]*Entry, error)
+
+	Set(Scope, string, string) error
+	SetBool(Scope, string, bool) error
+	SetInt(Scope, string, int64) error
+	Add(Scope, string, string) error
+	Unset(Scope, string) error
+	UnsetAll(Scope, string) error
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// MatchIncludeCondition reports whether the condition of an
+// includeIf.<condition>.path directive holds for a repository with
+// the given git directory and current branch. The gitdir:, gitdir/i:
+// and onbranch: conditions are supported, using the same pattern
+// rules as git; unknown conditions never match.
+func MatchIncludeCondition(condition, gitDir, branch string) bool {
+	switch {
+	case strings.HasPrefix(condition, "gitdir:"):
+		return operations.Wildmatch(gitDirPattern(strings.TrimPrefix(condition, "gitdir:")), gitDir, operations.WildmatchPathname)
+	case strings.HasPrefix(condition, "gitdir/i:"):
+		return operations.Wildmatch(gitDirPattern(strings.TrimPrefix(condition, "gitdir/i:")), gitDir,
+			operations.WildmatchPathname|operations.WildmatchCaseFold)
+	case strings.HasPrefix(condition, "onbranch:"):
+		if branch == "" {
+			return false
+		}
+
+		pattern := strings.TrimPrefix(condition, "onbranch:")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		return operations.Wildmatch(pattern, branch, operations.WildmatchPathname)
+	default:
+		return false
+	}
+}
+
+// gitDirPattern expands a gitdir: pattern the way git does: "~/" is
+// the home directory, relative patterns match at any depth, and a
+// trailing slash matches everything below that directory.
+func gitDirPattern(pattern string) string {
+	if strings.HasPrefix(pattern, "~/") {
+		if u, err := user.Current(); err == nil {
+			pattern = filepath.Join(u.HomeDir, pattern[2:]) + trailingSlash(pattern)
+		}
+	}
+
+	if !strings.HasPrefix(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	return pattern
+}
+
+func trailingSlash(pattern string) string {
+	if strings.HasSuffix(pattern, "/") {
+		return "/"
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type IncludeSuite struct{}
+
+var _ = Suite(&IncludeSuite{})
+
+func (s *IncludeSuite) TestGitDirConditions(c *C) {
+	gitDir := "/home/user/work/project/.git"
+
+	c.Assert(MatchIncludeCondition("gitdir:/home/user/work/", gitDir, ""), Equals, true)
+	c.Assert(MatchIncludeCondition("gitdir:/home/user/play/", gitDir, ""), Equals, false)
+	c.Assert(MatchIncludeCondition("gitdir:work/", gitDir, ""), Equals, true)
+	c.Assert(MatchIncludeCondition("gitdir:project/.git", gitDir, ""), Equals, true)
+	c.Assert(MatchIncludeCondition("gitdir:/home/*/work/", gitDir, ""), Equals, true)
+	c.Assert(MatchIncludeCondition("gitdir:/home/*/", gitDir, ""), Equals, true)
+	c.Assert(MatchIncludeCondition("gitdir:/home/*/project/", gitDir, ""), Equals, false)
+
+	c.Assert(MatchIncludeCondition("gitdir:/HOME/USER/work/", gitDir, ""), Equals, false)
+	c.Assert(MatchIncludeCondition("gitdir/i:/HOME/USER/work/", gitDir, ""), Equals, true)
+}
+
+func (s *IncludeSuite) TestBranchConditions(c *C) {
+	c.Assert(MatchIncludeCondition("onbranch:master", "", "master"), Equals, true)
+	c.Assert(MatchIncludeCondition("onbranch:master", "", "main"), Equals, false)
+	c.Assert(MatchIncludeCondition("onbranch:feature/", "", "feature/one/two"), Equals, true)
+	c.Assert(MatchIncludeCondition("onbranch:feature/*", "", "feature/one"), Equals, true)
+	c.Assert(MatchIncludeCondition("onbranch:feature/*", "", "feature/one/two"), Equals, false)
+	c.Assert(MatchIncludeCondition("onbranch:master", "", ""), Equals, false)
+}
+
+func (s *IncludeSuite) TestUnknownConditionsNeverMatch(c *C) {
+	c.Assert(MatchIncludeCondition("hasconfig:remote.*.url:foo", "/src/.git", "master"), Equals, false)
+	c.Assert(MatchIncludeCondition("", "/src/.git", "master"), Equals, false)
+}
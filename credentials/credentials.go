@@ -0,0 +1,169 @@
+// Package credentials provides pluggable authentication for the
+// network operations (clone, fetch, pull and push) of both
+// Repository implementations. A Provider resolves the credential to
+// use for a remote URL; the implementations translate that credential
+// into libgit2 credential callbacks or into the environment of the
+// git binary.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Kind identifies the authentication mechanism of a Credential.
+type Kind int
+
+const (
+	// Ambient leaves authentication to the ambient configuration
+	// of the implementation.
+	Ambient Kind = iota
+
+	// Key authenticates over SSH with a key file.
+	Key
+
+	// Agent authenticates over SSH with an ssh-agent.
+	Agent
+
+	// Plaintext authenticates with a username and password, or
+	// token, over HTTP(S).
+	Plaintext
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Ambient:
+		return "ambient"
+	case Key:
+		return "key"
+	case Agent:
+		return "agent"
+	case Plaintext:
+		return "plaintext"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Credential holds the secret material for a single authentication
+// attempt. Which fields are meaningful depends on the Kind.
+type Credential struct {
+	Kind       Kind
+	Username   string
+	Password   string
+	PublicKey  string
+	PrivateKey string
+	Passphrase string
+}
+
+// ErrNoCredentials is returned by providers that have no credential
+// for a URL. Chains use it to fall through to the next provider.
+var ErrNoCredentials = errors.New("no credentials available")
+
+// Provider returns the credential to use for a remote URL. The
+// username is the user named in the URL, if any.
+type Provider interface {
+	Credentials(remoteURL, username string) (*Credential, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(string, string) (*Credential, error)
+
+func (f ProviderFunc) Credentials(remoteURL, username string) (*Credential, error) {
+	return f(remoteURL, username)
+}
+
+// SSHKeyFile authenticates with a private key on disk. The public key
+// path may be empty, in which case the key's ".pub" file is used.
+func SSHKeyFile(username, privateKey, publicKey, passphrase string) Provider {
+	if publicKey == "" {
+		publicKey = privateKey + ".pub"
+	}
+
+	return ProviderFunc(func(_, urlUser string) (*Credential, error) {
+		return &Credential{
+			Kind:       Key,
+			Username:   firstNonEmpty(username, urlUser, "git"),
+			PrivateKey: privateKey,
+			PublicKey:  publicKey,
+			Passphrase: passphrase,
+		}, nil
+	})
+}
+
+// SSHAgent authenticates with the keys held by the running ssh-agent.
+func SSHAgent(username string) Provider {
+	return ProviderFunc(func(_, urlUser string) (*Credential, error) {
+		return &Credential{
+			Kind:     Agent,
+			Username: firstNonEmpty(username, urlUser, "git"),
+		}, nil
+	})
+}
+
+// Password authenticates with a fixed username and password, over
+// HTTP(S).
+func Password(username, password string) Provider {
+	return ProviderFunc(func(_, _ string) (*Credential, error) {
+		return &Credential{
+			Kind:     Plaintext,
+			Username: username,
+			Password: password,
+		}, nil
+	})
+}
+
+// Token authenticates over HTTP(S) with an access token, which most
+// hosting services accept as the password for any username.
+func Token(token string) Provider {
+	return ProviderFunc(func(_, urlUser string) (*Credential, error) {
+		return &Credential{
+			Kind:     Plaintext,
+			Username: firstNonEmpty(urlUser, "x-access-token"),
+			Password: token,
+		}, nil
+	})
+}
+
+// Chain returns a provider that tries each provider in turn, and
+// returns the first credential found.
+func Chain(providers ...Provider) Provider {
+	return ProviderFunc(func(remoteURL, username string) (*Credential, error) {
+		for _, p := range providers {
+			cred, err := p.Credentials(remoteURL, username)
+			if err == ErrNoCredentials {
+				continue
+			}
+
+			return cred, err
+		}
+
+		return nil, ErrNoCredentials
+	})
+}
+
+// ParseURL splits a remote URL into its scheme, host, path and
+// username, and understands the scp-like "user@host:path" syntax
+// used for SSH remotes.
+func ParseURL(remoteURL string) (*url.URL, error) {
+	if !strings.Contains(remoteURL, "://") {
+		if idx := strings.Index(remoteURL, ":"); idx > 0 && !strings.Contains(remoteURL[:idx], "/") {
+			remoteURL = "ssh://" + remoteURL[:idx] + "/" + strings.TrimPrefix(remoteURL[idx+1:], "/")
+		} else {
+			remoteURL = "file://" + remoteURL
+		}
+	}
+
+	return url.Parse(remoteURL)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
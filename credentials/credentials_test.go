@@ -0,0 +1,128 @@
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type CredentialsSuite struct {
+	dir string
+}
+
+var _ = Suite(&CredentialsSuite{})
+
+func (s *CredentialsSuite) SetUpSuite(c *C) {
+	dir, err := ioutil.TempDir("", "gitgone-credentials-")
+	c.Assert(err, IsNil)
+	s.dir = dir
+}
+
+func (s *CredentialsSuite) TearDownSuite(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *CredentialsSuite) TestParseURL(c *C) {
+	u, err := ParseURL("git@github.com:tychoish/gitgone.git")
+	c.Assert(err, IsNil)
+	c.Assert(u.Scheme, Equals, "ssh")
+	c.Assert(u.User.Username(), Equals, "git")
+	c.Assert(u.Host, Equals, "github.com")
+	c.Assert(u.Path, Equals, "/tychoish/gitgone.git")
+
+	u, err = ParseURL("https://user@example.com:8443/repo.git")
+	c.Assert(err, IsNil)
+	c.Assert(u.Scheme, Equals, "https")
+	c.Assert(u.Hostname(), Equals, "example.com")
+
+	u, err = ParseURL("/srv/git/repo.git")
+	c.Assert(err, IsNil)
+	c.Assert(u.Scheme, Equals, "file")
+	c.Assert(u.Path, Equals, "/srv/git/repo.git")
+}
+
+func (s *CredentialsSuite) TestNetrc(c *C) {
+	path := filepath.Join(s.dir, "netrc")
+	err := ioutil.WriteFile(path, []byte(`
+# comments are ignored
+machine example.com login alice password secret
+  # indented comments are ignored too
+machine hash.example.com login carol password pass#word
+machine git.example.com
+	login bob
+	password hunter2
+macdef init
+machine ignored.example.com login nobody password nothing
+
+default login anonymous password guest
+`), 0600)
+	c.Assert(err, IsNil)
+
+	provider := Netrc(path)
+
+	cred, err := provider.Credentials("https://example.com/repo.git", "")
+	c.Assert(err, IsNil)
+	c.Assert(cred.Kind, Equals, Plaintext)
+	c.Assert(cred.Username, Equals, "alice")
+	c.Assert(cred.Password, Equals, "secret")
+
+	cred, err = provider.Credentials("https://git.example.com/repo.git", "bob")
+	c.Assert(err, IsNil)
+	c.Assert(cred.Password, Equals, "hunter2")
+
+	cred, err = provider.Credentials("https://ignored.example.com/repo.git", "")
+	c.Assert(err, IsNil)
+	c.Assert(cred.Username, Equals, "anonymous")
+
+	cred, err = provider.Credentials("https://hash.example.com/repo.git", "")
+	c.Assert(err, IsNil)
+	c.Assert(cred.Password, Equals, "pass#word")
+
+	_, err = Netrc(filepath.Join(s.dir, "missing")).Credentials("https://example.com/", "")
+	c.Assert(err, Equals, ErrNoCredentials)
+}
+
+func (s *CredentialsSuite) TestNetrcEnvironment(c *C) {
+	first := filepath.Join(s.dir, "first-netrc")
+	second := filepath.Join(s.dir, "second-netrc")
+	c.Assert(ioutil.WriteFile(first, []byte("machine example.com login alice password one\n"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(second, []byte("machine example.com login alice password two\n"), 0600), IsNil)
+
+	defer os.Setenv("NETRC", os.Getenv("NETRC"))
+	provider := Netrc("")
+
+	// $NETRC is read for each lookup, rather than once.
+	for _, netrc := range []struct{ path, password string }{{first, "one"}, {second, "two"}} {
+		c.Assert(os.Setenv("NETRC", netrc.path), IsNil)
+		cred, err := provider.Credentials("https://example.com/repo.git", "")
+		c.Assert(err, IsNil)
+		c.Assert(cred.Password, Equals, netrc.password)
+	}
+}
+
+func (s *CredentialsSuite) TestChainFallsThrough(c *C) {
+	empty := ProviderFunc(func(string, string) (*Credential, error) {
+		return nil, ErrNoCredentials
+	})
+
+	cred, err := Chain(empty, Token("abc")).Credentials("https://example.com/repo.git", "")
+	c.Assert(err, IsNil)
+	c.Assert(cred.Username, Equals, "x-access-token")
+	c.Assert(cred.Password, Equals, "abc")
+
+	_, err = Chain(empty, empty).Credentials("https://example.com/repo.git", "")
+	c.Assert(err, Equals, ErrNoCredentials)
+}
+
+func (s *CredentialsSuite) TestSSHKeyFileDefaults(c *C) {
+	cred, err := SSHKeyFile("", "/keys/id_ed25519", "", "").Credentials("ssh://deploy@example.com/repo.git", "deploy")
+	c.Assert(err, IsNil)
+	c.Assert(cred.Kind, Equals, Key)
+	c.Assert(cred.Username, Equals, "deploy")
+	c.Assert(cred.PublicKey, Equals, "/keys/id_ed25519.pub")
+}
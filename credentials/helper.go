@@ -0,0 +1,63 @@
+package credentials
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Helper resolves credentials with "git credential fill", which
+// consults whichever credential helpers are configured for git,
+// e.g. a system keychain. The directory is the repository whose
+// configuration selects the helpers, and may be empty.
+func Helper(dir string) Provider {
+	return ProviderFunc(func(remoteURL, username string) (*Credential, error) {
+		u, err := ParseURL(remoteURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var input bytes.Buffer
+		fmt.Fprintf(&input, "protocol=%s\n", u.Scheme)
+		fmt.Fprintf(&input, "host=%s\n", u.Host)
+		fmt.Fprintf(&input, "path=%s\n", strings.TrimPrefix(u.Path, "/"))
+		if username != "" {
+			fmt.Fprintf(&input, "username=%s\n", username)
+		}
+		input.WriteString("\n")
+
+		cmd := exec.Command("git", "credential", "fill")
+		cmd.Dir = dir
+		cmd.Stdin = &input
+		// never fall back to prompting on the terminal
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, ErrNoCredentials
+		}
+
+		cred := &Credential{Kind: Plaintext}
+		for _, line := range strings.Split(string(output), "\n") {
+			kv := strings.SplitN(line, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			switch kv[0] {
+			case "username":
+				cred.Username = kv[1]
+			case "password":
+				cred.Password = kv[1]
+			}
+		}
+
+		if cred.Password == "" {
+			return nil, ErrNoCredentials
+		}
+
+		return cred, nil
+	})
+}
@@ -0,0 +1,140 @@
+package credentials
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// Netrc reads HTTP(S) credentials from a netrc file. If the path is
+// empty, the file in $NETRC or ~/.netrc is used.
+func Netrc(path string) Provider {
+	return ProviderFunc(func(remoteURL, username string) (*Credential, error) {
+		filename := path
+		if filename == "" {
+			filename = defaultNetrcPath()
+		}
+
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrNoCredentials
+			}
+			return nil, err
+		}
+
+		u, err := ParseURL(remoteURL)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range parseNetrc(string(data)) {
+			if m.machine != "" && m.machine != u.Hostname() && m.machine != u.Host {
+				continue
+			}
+
+			if username != "" && m.login != "" && m.login != username {
+				continue
+			}
+
+			return &Credential{
+				Kind:     Plaintext,
+				Username: m.login,
+				Password: m.password,
+			}, nil
+		}
+
+		return nil, ErrNoCredentials
+	})
+}
+
+func defaultNetrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return ".netrc"
+	}
+
+	return filepath.Join(u.HomeDir, ".netrc")
+}
+
+type netrcMachine struct {
+	machine  string
+	login    string
+	password string
+}
+
+// parseNetrc returns the entries of a netrc file in order, with the
+// "default" entry, which has an empty machine name, last.
+func parseNetrc(data string) []netrcMachine {
+	var (
+		machines []netrcMachine
+		fallback *netrcMachine
+		current  *netrcMachine
+		inMacro  bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// macro definitions run until the next blank line.
+		if inMacro {
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+
+		// only whole lines are comments, so that passwords may
+		// contain "#".
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			var value string
+			if i+1 < len(fields) {
+				value = fields[i+1]
+			}
+
+			switch fields[i] {
+			case "machine":
+				machines = append(machines, netrcMachine{machine: value})
+				current = &machines[len(machines)-1]
+				i++
+			case "default":
+				fallback = &netrcMachine{}
+				current = fallback
+			case "login":
+				if current != nil {
+					current.login = value
+				}
+				i++
+			case "password":
+				if current != nil {
+					current.password = value
+				}
+				i++
+			case "account":
+				i++
+			case "macdef":
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+
+	if fallback != nil {
+		machines = append(machines, *fallback)
+	}
+
+	return machines
+}
@@ -0,0 +1,245 @@
+package gitrect
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// archiveEntry is a file, directory or symbolic link in an archive.
+// Directory names end with a slash.
+type archiveEntry struct {
+	name     string
+	mode     os.FileMode
+	contents []byte
+}
+
+type archiveWriter interface {
+	Write(*archiveEntry) error
+	Close() error
+}
+
+func (self *repository) Archive(rev string, format operations.ArchiveFormat, prefix string, writer io.Writer) error {
+	if err := format.Validate(); err != nil {
+		return err
+	}
+
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		return err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	modified := commit.Committer().When
+	var archive archiveWriter
+	switch format {
+	case operations.ArchiveZip:
+		archive = newZipArchive(writer, modified, commit.Id().String())
+	case operations.ArchiveTarGzip:
+		archive, err = newTarArchive(gzip.NewWriter(writer), modified, commit.Id().String())
+	default:
+		archive, err = newTarArchive(nopCloser{writer}, modified, commit.Id().String())
+	}
+	if err != nil {
+		return err
+	}
+
+	if prefix != "" && strings.HasSuffix(prefix, "/") {
+		if err = archive.Write(&archiveEntry{name: prefix, mode: os.ModeDir | 0775}); err != nil {
+			return err
+		}
+	}
+
+	if err = self.archiveTree(archive, tree, prefix, "", nil); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// archiveTree writes the contents of a tree to the archive, omitting
+// paths with the export-ignore attribute in the .gitattributes files
+// of the tree, rather than those of the working tree.
+func (self *repository) archiveTree(archive archiveWriter, tree *git.Tree, prefix, dir string, rules []*operations.AttributeRule) error {
+	if entry := tree.EntryByName(".gitattributes"); entry != nil && entry.Type == git.ObjectBlob {
+		blob, err := self.repo.LookupBlob(entry.Id)
+		if err != nil {
+			return err
+		}
+		rules = append(rules[:len(rules):len(rules)], operations.ParseAttributeRules(dir, blob.Contents())...)
+	}
+
+	for i := uint64(0); i < tree.EntryCount(); i++ {
+		entry := tree.EntryByIndex(i)
+		name := path.Join(dir, entry.Name)
+
+		if isExportIgnored(rules, name) {
+			continue
+		}
+
+		switch entry.Type {
+		case git.ObjectTree:
+			if err := archive.Write(&archiveEntry{name: prefix + name + "/", mode: os.ModeDir | 0775}); err != nil {
+				return err
+			}
+
+			subtree, err := self.repo.LookupTree(entry.Id)
+			if err != nil {
+				return err
+			}
+
+			if err = self.archiveTree(archive, subtree, prefix, name, rules); err != nil {
+				return err
+			}
+		case git.ObjectCommit:
+			// submodules are archived as empty directories.
+			if err := archive.Write(&archiveEntry{name: prefix + name + "/", mode: os.ModeDir | 0775}); err != nil {
+				return err
+			}
+		case git.ObjectBlob:
+			blob, err := self.repo.LookupBlob(entry.Id)
+			if err != nil {
+				return err
+			}
+
+			mode := os.FileMode(0664)
+			switch entry.Filemode {
+			case git.FilemodeBlobExecutable:
+				mode = 0775
+			case git.FilemodeLink:
+				mode = os.ModeSymlink | 0777
+			}
+
+			if err = archive.Write(&archiveEntry{name: prefix + name, mode: mode, contents: blob.Contents()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isExportIgnored applies the rules in order, so that later rules,
+// and rules from deeper directories, take precedence.
+func isExportIgnored(rules []*operations.AttributeRule, name string) bool {
+	attrs := operations.ResolveAttributes(rules, name, "export-ignore")
+	return attrs[0].State == operations.AttributeSet
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+type tarArchive struct {
+	output   io.WriteCloser
+	tar      *tar.Writer
+	modified time.Time
+}
+
+// newTarArchive writes the commit id in a global header, as "git
+// archive" does, so that "git get-tar-commit-id" can recover it.
+func newTarArchive(output io.WriteCloser, modified time.Time, commit string) (*tarArchive, error) {
+	archive := &tarArchive{output: output, tar: tar.NewWriter(output), modified: modified}
+
+	err := archive.tar.WriteHeader(&tar.Header{
+		Typeflag:   tar.TypeXGlobalHeader,
+		Name:       "pax_global_header",
+		PAXRecords: map[string]string{"comment": commit},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+func (a *tarArchive) Write(entry *archiveEntry) error {
+	header := &tar.Header{
+		Name:    entry.name,
+		Mode:    int64(entry.mode.Perm()),
+		ModTime: a.modified,
+		Uname:   "root",
+		Gname:   "root",
+	}
+
+	switch {
+	case entry.mode.IsDir():
+		header.Typeflag = tar.TypeDir
+	case entry.mode&os.ModeSymlink != 0:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = string(entry.contents)
+	default:
+		header.Typeflag = tar.TypeReg
+		header.Size = int64(len(entry.contents))
+	}
+
+	if err := a.tar.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if header.Typeflag == tar.TypeReg {
+		_, err := a.tar.Write(entry.contents)
+		return err
+	}
+
+	return nil
+}
+
+func (a *tarArchive) Close() error {
+	if err := a.tar.Close(); err != nil {
+		return err
+	}
+
+	return a.output.Close()
+}
+
+type zipArchive struct {
+	zip      *zip.Writer
+	modified time.Time
+}
+
+func newZipArchive(output io.Writer, modified time.Time, commit string) *zipArchive {
+	archive := &zipArchive{zip: zip.NewWriter(output), modified: modified}
+	archive.zip.SetComment(commit)
+
+	return archive
+}
+
+func (a *zipArchive) Write(entry *archiveEntry) error {
+	header := &zip.FileHeader{
+		Name:     entry.name,
+		Method:   zip.Deflate,
+		Modified: a.modified,
+	}
+	if entry.mode.IsDir() {
+		header.Method = zip.Store
+	}
+	header.SetMode(entry.mode)
+
+	file, err := a.zip.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(entry.contents)
+	return err
+}
+
+func (a *zipArchive) Close() error {
+	return a.zip.Close()
+}
@@ -0,0 +1,64 @@
+package gitrect
+
+import (
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) Blame(rev, path string, opts operations.BlameOptions) (*operations.BlameResult, error) {
+	if opts.IgnoreRevsFile != "" {
+		return nil, unsupported("blame with ignored revisions")
+	}
+	if opts.IgnoreWhitespace {
+		return nil, unsupported("blame ignoring whitespace")
+	}
+
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	blameOpts, err := git.DefaultBlameOptions()
+	if err != nil {
+		return nil, err
+	}
+	blameOpts.NewestCommit = commit.Id()
+	if opts.StartLine > 0 {
+		blameOpts.MinLine = uint32(opts.StartLine)
+	}
+	if opts.EndLine > 0 {
+		blameOpts.MaxLine = uint32(opts.EndLine)
+	}
+
+	blame, err := self.repo.BlameFile(path, &blameOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer blame.Free()
+
+	result := &operations.BlameResult{Path: path}
+	for i := 0; i < blame.HunkCount(); i++ {
+		hunk, err := blame.HunkByIndex(i)
+		if err != nil {
+			return nil, err
+		}
+
+		blamed := &operations.BlameHunk{
+			Commit:        hunk.FinalCommitId.String(),
+			OriginalPath:  hunk.OrigPath,
+			OriginalStart: int(hunk.OrigStartLineNumber),
+			Start:         int(hunk.FinalStartLineNumber),
+			Lines:         int(hunk.LinesInHunk),
+		}
+		if hunk.FinalSignature != nil {
+			blamed.Author = hunk.FinalSignature.Name
+			blamed.AuthorEmail = hunk.FinalSignature.Email
+			blamed.AuthorTime = hunk.FinalSignature.When
+		}
+
+		result.Hunks = append(result.Hunks, blamed)
+	}
+
+	return result, nil
+}
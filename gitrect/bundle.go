@@ -0,0 +1,250 @@
+package gitrect
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// CreateBundle writes a bundle of the given refs and ranges, or of
+// every ref when none are given. Ranges may be given as "A..B" or as
+// a ref followed by "^A".
+func (self *repository) CreateBundle(bundle io.Writer, revs ...string) error {
+	if len(revs) == 0 {
+		revs = []string{"--all"}
+	}
+
+	header := &operations.Bundle{Version: 2}
+	var include, exclude []*git.Oid
+	var tags []*git.Oid
+
+	// addRef adds a ref to the bundle. When bundling every ref, refs
+	// that do not peel to a commit, like tags of trees, are skipped
+	// rather than failing the bundle.
+	addRef := func(ref *git.Reference, all bool) error {
+		resolved, err := ref.Resolve()
+		if err != nil {
+			return err
+		}
+
+		obj, err := self.repo.Lookup(resolved.Target())
+		if err != nil {
+			return err
+		}
+
+		commit, err := obj.Peel(git.ObjectCommit)
+		if err != nil {
+			if all {
+				return nil
+			}
+			return fmt.Errorf("%s does not refer to a commit", ref.Name())
+		}
+
+		if obj.Type() == git.ObjectTag {
+			tags = append(tags, obj.Id())
+		}
+
+		header.Refs = append(header.Refs, &operations.BundleRef{Commit: obj.Id().String(), Name: ref.Name()})
+		include = append(include, commit.Id())
+		return nil
+	}
+
+	for _, rev := range revs {
+		switch {
+		case rev == "--all":
+			iter, err := self.repo.NewReferenceIteratorGlob("refs/*")
+			if err != nil {
+				return err
+			}
+
+			for {
+				ref, err := iter.Next()
+				if err != nil {
+					if git.IsErrorCode(err, git.ErrIterOver) {
+						break
+					}
+					iter.Free()
+					return err
+				}
+
+				if err = addRef(ref, true); err != nil {
+					iter.Free()
+					return err
+				}
+			}
+			iter.Free()
+
+			// like git, include HEAD, unless it is unborn
+			head, err := self.repo.References.Lookup("HEAD")
+			if err != nil {
+				return err
+			}
+			if _, err = head.Resolve(); err == nil {
+				if err = addRef(head, true); err != nil {
+					return err
+				}
+			}
+		case strings.HasPrefix(rev, "^"):
+			commit, err := self.lookupRevCommit(rev[1:])
+			if err != nil {
+				return err
+			}
+			exclude = append(exclude, commit.Id())
+		case strings.Contains(rev, ".."):
+			until, since := operations.SplitPatchRange(rev)
+			commit, err := self.lookupRevCommit(since)
+			if err != nil {
+				return err
+			}
+			exclude = append(exclude, commit.Id())
+
+			ref, err := self.repo.References.Dwim(until)
+			if err != nil {
+				return fmt.Errorf("%s is not a ref", until)
+			}
+			if err = addRef(ref, false); err != nil {
+				return err
+			}
+		default:
+			ref, err := self.repo.References.Dwim(rev)
+			if err != nil {
+				return fmt.Errorf("%s is not a ref", rev)
+			}
+			if err = addRef(ref, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	walk, err := self.bundleWalk(include, exclude)
+	if err != nil {
+		return err
+	}
+	defer walk.Free()
+
+	// prerequisites are the parents of bundled commits that are
+	// not themselves in the bundle.
+	commits := map[string]*git.Commit{}
+	err = walk.Iterate(func(commit *git.Commit) bool {
+		commits[commit.Id().String()] = commit
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("refusing to create an empty bundle")
+	}
+
+	seen := map[string]bool{}
+	for _, commit := range commits {
+		for i := uint(0); i < commit.ParentCount(); i++ {
+			id := commit.ParentId(i).String()
+			if _, ok := commits[id]; ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			prereq := &operations.BundleRef{Commit: id}
+			if parent := commit.Parent(i); parent != nil {
+				prereq.Name = parent.Summary()
+			}
+			header.Prerequisites = append(header.Prerequisites, prereq)
+		}
+	}
+
+	packer, err := self.repo.NewPackbuilder()
+	if err != nil {
+		return err
+	}
+	defer packer.Free()
+
+	walk, err = self.bundleWalk(include, exclude)
+	if err != nil {
+		return err
+	}
+	defer walk.Free()
+
+	if err = packer.InsertWalk(walk); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err = packer.Insert(tag, ""); err != nil {
+			return err
+		}
+	}
+
+	if err = operations.WriteBundleHeader(bundle, header); err != nil {
+		return err
+	}
+
+	return packer.Write(bundle)
+}
+
+func (self *repository) bundleWalk(include, exclude []*git.Oid) (*git.RevWalk, error) {
+	walk, err := self.repo.Walk()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range include {
+		if err = walk.Push(id); err != nil {
+			walk.Free()
+			return nil, err
+		}
+	}
+	for _, id := range exclude {
+		if err = walk.Hide(id); err != nil {
+			walk.Free()
+			return nil, err
+		}
+	}
+
+	return walk, nil
+}
+
+// VerifyBundle reads the header of a bundle, and returns an error
+// if the repository is missing any of the bundle's prerequisites.
+func (self *repository) VerifyBundle(path string) (*operations.Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	bundle, err := operations.ReadBundleHeader(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read bundle %s: %s", path, err)
+	}
+
+	odb, err := self.repo.Odb()
+	if err != nil {
+		return bundle, err
+	}
+
+	var missing []string
+	for _, prereq := range bundle.Prerequisites {
+		id, err := git.NewOid(prereq.Commit)
+		if err != nil || !odb.Exists(id) {
+			missing = append(missing, prereq.Commit)
+		}
+	}
+
+	if len(missing) > 0 {
+		return bundle, fmt.Errorf("bundle %s is not valid for this repository, missing: %s",
+			path, strings.Join(missing, ", "))
+	}
+
+	return bundle, nil
+}
+
+// libgit2 0.23 can neither fetch from bundles nor index a packfile
+// into an existing repository.
+func (self *repository) FetchFromBundle(_ string, _ ...string) (*operations.FetchResult, error) {
+	return nil, unsupported("fetching from bundles")
+}
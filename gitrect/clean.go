@@ -0,0 +1,85 @@
+package gitrect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) Clean(opts operations.CleanOptions) ([]string, error) {
+	if self.repo.IsBare() || !self.exists {
+		return nil, fmt.Errorf("cannot modify the working tree of this repository")
+	}
+
+	// without recursing, libgit2 reports untracked and ignored
+	// directories as a single entry ending in a slash, as git clean
+	// does.
+	statusOpts := &git.StatusOptions{
+		Show:     git.StatusShowWorkdirOnly,
+		Flags:    git.StatusOptIncludeUntracked,
+		Pathspec: opts.Pathspecs,
+	}
+	if opts.IgnoredOnly || opts.IncludeIgnored {
+		statusOpts.Flags |= git.StatusOptIncludeIgnored
+	}
+
+	status, err := self.repo.StatusList(statusOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer status.Free()
+
+	count, err := status.EntryCount()
+	if err != nil {
+		return nil, err
+	}
+
+	workdir := self.repo.Workdir()
+	var paths []string
+	for i := 0; i < count; i++ {
+		entry, err := status.ByIndex(i)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case entry.Status&git.StatusIgnored != 0:
+			if !opts.IgnoredOnly && !opts.IncludeIgnored {
+				continue
+			}
+		case entry.Status&git.StatusWtNew != 0:
+			if opts.IgnoredOnly {
+				continue
+			}
+		default:
+			continue
+		}
+
+		path := entry.IndexToWorkdir.NewFile.Path
+		if strings.HasSuffix(path, "/") {
+			if !opts.Directories {
+				continue
+			}
+
+			// like git clean, leave nested repositories alone.
+			if _, err := os.Stat(filepath.Join(workdir, path, ".git")); err == nil {
+				continue
+			}
+		}
+
+		if !opts.DryRun {
+			if err = os.RemoveAll(filepath.Join(workdir, path)); err != nil {
+				return paths, err
+			}
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,264 @@
+package gitrect
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// setTreeEntry returns a new tree in which the entry at the path is
+// replaced, or removed if the id is nil, building on the approach of
+// treeAdd in the store package. Only the trees along the path are
+// rewritten, and directories left empty are removed, since git does
+// not record empty trees.
+func (self *repository) setTreeEntry(tree *git.Tree, parts []string, id *git.Oid, mode git.Filemode) (*git.Oid, error) {
+	var builder *git.TreeBuilder
+	var err error
+	if tree == nil {
+		builder, err = self.repo.TreeBuilder()
+	} else {
+		builder, err = self.repo.TreeBuilderFromTree(tree)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer builder.Free()
+
+	if len(parts) > 1 {
+		var subtree *git.Tree
+		if tree != nil {
+			if entry := tree.EntryByName(parts[0]); entry != nil && entry.Type == git.ObjectTree {
+				if subtree, err = self.repo.LookupTree(entry.Id); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		id, err = self.setTreeEntry(subtree, parts[1:], id, mode)
+		if err != nil {
+			return nil, err
+		}
+
+		written, err := self.repo.LookupTree(id)
+		if err != nil {
+			return nil, err
+		}
+
+		mode = git.FilemodeTree
+		if written.EntryCount() == 0 {
+			id = nil
+		}
+	}
+
+	if id == nil {
+		if tree != nil && tree.EntryByName(parts[0]) != nil {
+			if err = builder.Remove(parts[0]); err != nil {
+				return nil, err
+			}
+		}
+	} else if err = builder.Insert(parts[0], id, mode); err != nil {
+		return nil, err
+	}
+
+	return builder.Write()
+}
+
+// applyChange applies a change to a tree and returns the new tree.
+func (self *repository) applyChange(tree *git.Tree, change *operations.FileChange) (*git.Tree, error) {
+	parts, err := operations.SplitTreePath(change.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing *git.TreeEntry
+	if tree != nil {
+		existing, _ = tree.EntryByPath(strings.Join(parts, "/"))
+	}
+
+	var id *git.Oid
+	switch change.Kind {
+	case operations.FileWrite:
+		blob, err := self.repo.CreateBlobFromBuffer(change.Contents)
+		if err != nil {
+			return nil, err
+		}
+
+		mode := git.Filemode(change.Mode)
+		if mode == 0 {
+			mode = git.FilemodeBlob
+			if existing != nil && operations.FileMode(existing.Filemode).IsFile() {
+				mode = existing.Filemode
+			}
+		}
+
+		id, err = self.setTreeEntry(tree, parts, blob, mode)
+	case operations.FileDelete:
+		if existing == nil {
+			return nil, fmt.Errorf("cannot delete %s, it does not exist", change.Path)
+		}
+
+		id, err = self.setTreeEntry(tree, parts, nil, 0)
+	case operations.FileRename:
+		from, err := operations.SplitTreePath(change.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var source *git.TreeEntry
+		if tree != nil {
+			source, _ = tree.EntryByPath(strings.Join(from, "/"))
+		}
+		if source == nil {
+			return nil, fmt.Errorf("cannot rename %s, it does not exist", change.From)
+		}
+
+		// copy the entry, since it belongs to the old tree.
+		sourceID, sourceMode := source.Id, source.Filemode
+
+		id, err = self.setTreeEntry(tree, from, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if tree, err = self.repo.LookupTree(id); err != nil {
+			return nil, err
+		}
+
+		id, err = self.setTreeEntry(tree, parts, sourceID, sourceMode)
+	case operations.FileSetMode:
+		if existing == nil || !operations.FileMode(existing.Filemode).IsFile() || !change.Mode.IsFile() {
+			return nil, fmt.Errorf("cannot change the mode of %s", change.Path)
+		}
+
+		id, err = self.setTreeEntry(tree, parts, existing.Id, git.Filemode(change.Mode))
+	default:
+		return nil, fmt.Errorf("unknown change to %s", change.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return self.repo.LookupTree(id)
+}
+
+// signatureFor converts an identity to a signature, falling back to
+// the configured identity.
+func (self *repository) signatureFor(ident *operations.Identity) (*git.Signature, error) {
+	if ident == nil {
+		return self.repo.DefaultSignature()
+	}
+
+	when := ident.When
+	if when.IsZero() {
+		when = time.Now()
+	}
+
+	return &git.Signature{Name: ident.Name, Email: ident.Email, When: when}, nil
+}
+
+func (self *repository) WriteCommit(req operations.CommitRequest) (string, error) {
+	var tree *git.Tree
+	var parents []*git.Commit
+	if req.Parent != "" {
+		parent, err := self.lookupRevCommit(req.Parent)
+		if err != nil {
+			return "", err
+		}
+		parents = append(parents, parent)
+
+		if tree, err = parent.Tree(); err != nil {
+			return "", err
+		}
+	}
+
+	var err error
+	for _, change := range req.Changes {
+		if tree, err = self.applyChange(tree, change); err != nil {
+			return "", err
+		}
+	}
+
+	if tree == nil {
+		builder, err := self.repo.TreeBuilder()
+		if err != nil {
+			return "", err
+		}
+		defer builder.Free()
+
+		id, err := builder.Write()
+		if err != nil {
+			return "", err
+		}
+		if tree, err = self.repo.LookupTree(id); err != nil {
+			return "", err
+		}
+	}
+
+	author, err := self.signatureFor(req.Author)
+	if err != nil {
+		return "", err
+	}
+	committer, err := self.signatureFor(req.Committer)
+	if err != nil {
+		return "", err
+	}
+
+	var commit *git.Oid
+	if self.signer != nil {
+		commit, err = self.writeSignedCommit(author, committer, req.Message, tree, parents...)
+	} else {
+		commit, err = self.repo.CreateCommit("", author, committer, req.Message, tree, parents...)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if req.Ref == "" {
+		return commit.String(), nil
+	}
+
+	subject := strings.SplitN(strings.TrimSpace(req.Message), "\n", 2)[0]
+	if err = self.updateRef(req.Ref, commit, req.CompareAndSwap, req.Expected, "commit: "+subject); err != nil {
+		return commit.String(), err
+	}
+
+	return commit.String(), nil
+}
+
+// updateRef points a ref at the commit. With compare and swap, the
+// ref must have the expected value, or not exist if the expected
+// value is empty. Creating a ref without force fails if it exists,
+// and SetTarget fails if the ref no longer has the value it had when
+// it was looked up, which libgit2 checks while holding the ref's
+// lock, so both cases are atomic.
+func (self *repository) updateRef(name string, id *git.Oid, compareAndSwap bool, expected, message string) error {
+	if !compareAndSwap {
+		_, err := self.repo.References.Create(name, id, true, message)
+		return err
+	}
+
+	if expected == "" {
+		_, err := self.repo.References.Create(name, id, false, message)
+		return err
+	}
+
+	ref, err := self.repo.References.Lookup(name)
+	if err != nil {
+		return fmt.Errorf("could not update %s, expected %s: %s", name, expected, err)
+	}
+
+	current := ref.Target()
+	if current == nil || current.String() != expected {
+		return fmt.Errorf("could not update %s, expected %s but found %s", name, expected, current)
+	}
+
+	_, err = ref.SetTarget(id, message)
+	if err != nil {
+		return fmt.Errorf("could not update %s, expected %s: %s", name, expected, err)
+	}
+
+	return nil
+}
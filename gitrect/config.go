@@ -0,0 +1,423 @@
+package gitrect
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/config"
+)
+
+// libgit2 reserves the levels above ConfigLevelApp for applications,
+// which is where the worktree configuration and any files included
+// conditionally are added, as libgit2 does not support either. As a
+// result, values from these files take precedence over the local
+// configuration, rather than applying at the point of inclusion.
+const (
+	worktreeLevel git.ConfigLevel = git.ConfigLevelApp + iota
+	firstIncludeLevel
+)
+
+// repoConfig implements config.Config with libgit2's configuration
+// API.
+type repoConfig struct {
+	repo     *repository
+	includes map[git.ConfigLevel]string
+}
+
+func (self *repository) Config() config.Config {
+	return &repoConfig{repo: self}
+}
+
+func isNotFound(err error) bool {
+	gitErr, ok := err.(*git.GitError)
+	return ok && gitErr.Code == git.ErrNotFound
+}
+
+func (c *repoConfig) worktreeConfigPath() string {
+	return filepath.Join(c.repo.repo.Path(), "config.worktree")
+}
+
+// open returns the merged view of the repository's configuration
+// with the worktree configuration and conditional includes added.
+func (c *repoConfig) open() (*git.Config, error) {
+	cfg, err := c.repo.repo.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	worktree := c.worktreeConfigPath()
+	if _, err = os.Stat(worktree); err == nil {
+		enabled, err := cfg.LookupBool("extensions.worktreeconfig")
+		if err == nil && enabled {
+			if err = cfg.AddFile(worktree, worktreeLevel, true); err != nil {
+				cfg.Free()
+				return nil, err
+			}
+		}
+	}
+
+	if err = c.addConditionalIncludes(cfg); err != nil {
+		cfg.Free()
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// addConditionalIncludes evaluates every includeIf.<condition>.path
+// directive in the configuration and adds the files whose condition
+// holds for this repository.
+func (c *repoConfig) addConditionalIncludes(cfg *git.Config) error {
+	iter, err := cfg.NewIterator()
+	if err != nil {
+		return err
+	}
+	defer iter.Free()
+
+	var branch string
+	if head, err := c.repo.repo.Head(); err == nil && head.IsBranch() {
+		branch, _ = head.Branch().Name()
+	}
+	gitDir := strings.TrimSuffix(c.repo.repo.Path(), "/")
+
+	type include struct {
+		path  string
+		level git.ConfigLevel
+	}
+	var includes []include
+
+	for {
+		entry, err := iter.Next()
+		if err != nil {
+			if git.IsErrorCode(err, git.ErrIterOver) {
+				break
+			}
+			return err
+		}
+
+		if !strings.HasPrefix(entry.Name, "includeif.") || !strings.HasSuffix(entry.Name, ".path") {
+			continue
+		}
+
+		condition := strings.TrimSuffix(strings.TrimPrefix(entry.Name, "includeif."), ".path")
+		if config.MatchIncludeCondition(condition, gitDir, branch) {
+			includes = append(includes, include{entry.Value, entry.Level})
+		}
+	}
+
+	c.includes = make(map[git.ConfigLevel]string)
+	for idx, inc := range includes {
+		path := c.resolveIncludePath(inc.path, inc.level)
+		if _, err := os.Stat(path); err != nil {
+			// git silently ignores missing include files.
+			continue
+		}
+
+		level := firstIncludeLevel + git.ConfigLevel(idx)
+		if err := cfg.AddFile(path, level, true); err != nil {
+			return err
+		}
+		c.includes[level] = path
+	}
+
+	return nil
+}
+
+// resolveIncludePath expands "~/" and resolves relative include
+// paths against the directory of the file that included them.
+func (c *repoConfig) resolveIncludePath(path string, level git.ConfigLevel) string {
+	if strings.HasPrefix(path, "~/") {
+		if u, err := user.Current(); err == nil {
+			return filepath.Join(u.HomeDir, path[2:])
+		}
+	}
+
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	if origin := c.origin(level); origin != "" {
+		return filepath.Join(filepath.Dir(origin), path)
+	}
+
+	return path
+}
+
+// origin returns the path of the file that provides a configuration
+// level.
+func (c *repoConfig) origin(level git.ConfigLevel) string {
+	var path string
+
+	switch level {
+	case git.ConfigLevelSystem:
+		path, _ = git.ConfigFindSystem()
+	case git.ConfigLevelXDG:
+		path, _ = git.ConfigFindXDG()
+	case git.ConfigLevelGlobal:
+		path, _ = git.ConfigFindGlobal()
+	case git.ConfigLevelLocal:
+		path = filepath.Join(c.repo.repo.Path(), "config")
+	case worktreeLevel:
+		path = c.worktreeConfigPath()
+	default:
+		path = c.includes[level]
+	}
+
+	return path
+}
+
+func levelScope(level git.ConfigLevel) config.Scope {
+	switch level {
+	case git.ConfigLevelSystem:
+		return config.System
+	case git.ConfigLevelXDG, git.ConfigLevelGlobal:
+		return config.Global
+	case git.ConfigLevelLocal:
+		return config.Local
+	case worktreeLevel:
+		return config.Worktree
+	default:
+		return config.Any
+	}
+}
+
+// openScope returns a configuration object that reads from and
+// writes to only the file for the specified scope.
+func (c *repoConfig) openScope(scope config.Scope) (*git.Config, error) {
+	var level git.ConfigLevel
+
+	switch scope {
+	case config.System:
+		level = git.ConfigLevelSystem
+	case config.Global:
+		level = git.ConfigLevelGlobal
+	case config.Local:
+		level = git.ConfigLevelLocal
+	case config.Worktree:
+		return git.OpenOndisk(nil, c.worktreeConfigPath())
+	default:
+		return nil, fmt.Errorf("cannot write configuration to scope '%s'", scope)
+	}
+
+	cfg, err := c.repo.repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	defer cfg.Free()
+
+	return cfg.OpenLevel(cfg, level)
+}
+
+func (c *repoConfig) Get(key string) (string, error) {
+	cfg, err := c.open()
+	if err != nil {
+		return "", err
+	}
+	defer cfg.Free()
+
+	value, err := cfg.LookupString(key)
+	if isNotFound(err) {
+		return "", config.ErrKeyNotFound
+	}
+
+	return value, err
+}
+
+func (c *repoConfig) GetAll(key string) ([]string, error) {
+	cfg, err := c.open()
+	if err != nil {
+		return nil, err
+	}
+	defer cfg.Free()
+
+	iter, err := cfg.NewMultivarIterator(key, "")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Free()
+
+	var values []string
+	for {
+		entry, err := iter.Next()
+		if err != nil {
+			if git.IsErrorCode(err, git.ErrIterOver) {
+				break
+			}
+			return nil, err
+		}
+		values = append(values, entry.Value)
+	}
+
+	if len(values) == 0 {
+		return nil, config.ErrKeyNotFound
+	}
+
+	return values, nil
+}
+
+func (c *repoConfig) GetBool(key string) (bool, error) {
+	cfg, err := c.open()
+	if err != nil {
+		return false, err
+	}
+	defer cfg.Free()
+
+	value, err := cfg.LookupBool(key)
+	if isNotFound(err) {
+		return false, config.ErrKeyNotFound
+	}
+
+	return value, err
+}
+
+func (c *repoConfig) GetInt(key string) (int64, error) {
+	cfg, err := c.open()
+	if err != nil {
+		return 0, err
+	}
+	defer cfg.Free()
+
+	value, err := cfg.LookupInt64(key)
+	if isNotFound(err) {
+		return 0, config.ErrKeyNotFound
+	}
+
+	return value, err
+}
+
+func (c *repoConfig) List(scope config.Scope) ([]*config.Entry, error) {
+	var (
+		cfg *git.Config
+		err error
+	)
+
+	if scope == config.Any {
+		cfg, err = c.open()
+	} else {
+		cfg, err = c.openScope(scope)
+	}
+	if err != nil {
+		if isNotFound(err) {
+			return []*config.Entry{}, nil
+		}
+		return nil, err
+	}
+	defer cfg.Free()
+
+	iter, err := cfg.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Free()
+
+	entries := []*config.Entry{}
+	for {
+		entry, err := iter.Next()
+		if err != nil {
+			if git.IsErrorCode(err, git.ErrIterOver) {
+				break
+			}
+			return nil, err
+		}
+
+		e := &config.Entry{
+			Key:   entry.Name,
+			Value: entry.Value,
+			Scope: levelScope(entry.Level),
+		}
+
+		if scope == config.Worktree {
+			e.Scope = config.Worktree
+		}
+
+		if origin := c.origin(entry.Level); origin != "" {
+			e.Origin = "file:" + origin
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func (c *repoConfig) write(scope config.Scope, op func(*git.Config) error) error {
+	cfg, err := c.openScope(scope)
+	if err != nil {
+		return err
+	}
+	defer cfg.Free()
+
+	return op(cfg)
+}
+
+func (c *repoConfig) Set(scope config.Scope, key, value string) error {
+	return c.write(scope, func(cfg *git.Config) error {
+		return cfg.SetString(key, value)
+	})
+}
+
+func (c *repoConfig) SetBool(scope config.Scope, key string, value bool) error {
+	return c.write(scope, func(cfg *git.Config) error {
+		return cfg.SetBool(key, value)
+	})
+}
+
+func (c *repoConfig) SetInt(scope config.Scope, key string, value int64) error {
+	return c.write(scope, func(cfg *git.Config) error {
+		return cfg.SetInt64(key, value)
+	})
+}
+
+func (c *repoConfig) Add(scope config.Scope, key, value string) error {
+	return c.write(scope, func(cfg *git.Config) error {
+		// a regular expression that matches no existing value
+		// causes libgit2 to append a new value for the key.
+		return cfg.SetMultivar(key, "$^", value)
+	})
+}
+
+func (c *repoConfig) Unset(scope config.Scope, key string) error {
+	return c.write(scope, func(cfg *git.Config) error {
+		err := cfg.Delete(key)
+		if isNotFound(err) {
+			return config.ErrKeyNotFound
+		}
+		return err
+	})
+}
+
+func (c *repoConfig) UnsetAll(scope config.Scope, key string) error {
+	return c.write(scope, func(cfg *git.Config) error {
+		iter, err := cfg.NewMultivarIterator(key, "")
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for {
+			if _, err = iter.Next(); err != nil {
+				if git.IsErrorCode(err, git.ErrIterOver) {
+					break
+				}
+				iter.Free()
+				return err
+			}
+			count++
+		}
+		iter.Free()
+
+		switch count {
+		case 0:
+			return config.ErrKeyNotFound
+		case 1:
+			return cfg.Delete(key)
+		default:
+			return unsupported("removing all values of a multi-valued configuration key")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package gitrect
+
+import (
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/credentials"
+	"github.com/tychoish/grip"
+)
+
+// libgit2 calls the credentials callback again every time the remote
+// rejects a credential, so give up rather than loop forever.
+const maxCredentialAttempts = 3
+
+func (self *repository) SetCredentials(provider credentials.Provider) {
+	self.credentials = provider
+}
+
+// remoteCallbacks returns the callbacks used by every operation that
+// communicates with a remote.
+func (self *repository) remoteCallbacks() git.RemoteCallbacks {
+	callbacks := git.RemoteCallbacks{}
+	self.addProgressCallbacks(&callbacks)
+
+	if self.credentials == nil {
+		return callbacks
+	}
+
+	attempts := 0
+	callbacks.CredentialsCallback = func(url, username string, allowed git.CredType) (git.ErrorCode, *git.Cred) {
+		attempts++
+		if attempts > maxCredentialAttempts {
+			return git.ErrAuth, nil
+		}
+
+		cred, err := self.credentials.Credentials(url, username)
+		if err == credentials.ErrNoCredentials || (err == nil && cred == nil) {
+			cred = &credentials.Credential{Kind: credentials.Ambient}
+		} else if err != nil {
+			grip.CatchError(err)
+			return git.ErrAuth, nil
+		}
+
+		var (
+			ret     int
+			gitCred git.Cred
+		)
+
+		switch cred.Kind {
+		case credentials.Key:
+			ret, gitCred = git.NewCredSshKey(cred.Username, cred.PublicKey, cred.PrivateKey, cred.Passphrase)
+		case credentials.Agent:
+			ret, gitCred = git.NewCredSshKeyFromAgent(cred.Username)
+		case credentials.Plaintext:
+			ret, gitCred = git.NewCredUserpassPlaintext(cred.Username, cred.Password)
+		default:
+			ret, gitCred = git.NewCredDefault()
+		}
+
+		return git.ErrorCode(ret), &gitCred
+	}
+
+	return callbacks
+}
+
+func (self *repository) fetchOptions() *git.FetchOptions {
+	return &git.FetchOptions{RemoteCallbacks: self.remoteCallbacks()}
+}
+
+func (self *repository) pushOptions() *git.PushOptions {
+	return &git.PushOptions{RemoteCallbacks: self.remoteCallbacks()}
+}
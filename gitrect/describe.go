@@ -0,0 +1,207 @@
+package gitrect
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// maxDescribeCandidates is the number of tagged commits that git
+// considers before choosing the nearest.
+const maxDescribeCandidates = 10
+
+type describeTag struct {
+	name      string
+	annotated bool
+}
+
+// Describe finds the nearest tag the way git does: it walks history
+// from the revision, newest first, collects the first tagged commits,
+// and picks the one with the fewest commits between it and the
+// revision.
+func (self *repository) Describe(rev string, opts operations.DescribeOptions) (*operations.Description, error) {
+	if opts.Dirty && rev != "" && rev != "HEAD" {
+		return nil, fmt.Errorf("cannot check the working tree when describing '%s'", rev)
+	}
+
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := self.describeTags(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	walk, err := self.repo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+
+	walk.Sorting(git.SortTopological | git.SortTime)
+	if err = walk.Push(commit.Id()); err != nil {
+		return nil, err
+	}
+
+	var candidates []*git.Oid
+	err = walk.Iterate(func(c *git.Commit) bool {
+		if _, ok := tags[c.Id().String()]; ok {
+			candidates = append(candidates, c.Id())
+		}
+		return len(candidates) < maxDescribeCandidates
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 {
+		return nil, operations.ErrNoDescription
+	}
+
+	var best *git.Oid
+	distance := -1
+	for _, candidate := range candidates {
+		count, err := self.countCommits(commit.Id(), candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		if distance < 0 || count < distance {
+			best, distance = candidate, count
+		}
+	}
+
+	desc := &operations.Description{
+		Tag:      tags[best.String()].name,
+		Distance: distance,
+		Commit:   commit.Id().String()[:opts.AbbrevLength()],
+	}
+
+	if opts.Dirty {
+		desc.Dirty, err = self.isDirty()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return desc, nil
+}
+
+// describeTags returns the tags that Describe may use, by the id of
+// the commit they refer to. Annotated tags take precedence over
+// lightweight tags of the same commit.
+func (self *repository) describeTags(opts operations.DescribeOptions) (map[string]*describeTag, error) {
+	iter, err := self.repo.NewReferenceIteratorGlob("refs/tags/*")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Free()
+
+	tags := map[string]*describeTag{}
+	for {
+		ref, err := iter.Next()
+		if err != nil {
+			if git.IsErrorCode(err, git.ErrIterOver) {
+				break
+			}
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(ref.Name(), "refs/tags/")
+		if !matchDescribePatterns(opts, name) {
+			continue
+		}
+
+		obj, err := self.repo.Lookup(ref.Target())
+		if err != nil {
+			continue
+		}
+
+		annotated := obj.Type() == git.ObjectTag
+		if !annotated && !opts.Tags {
+			continue
+		}
+
+		peeled, err := obj.Peel(git.ObjectCommit)
+		if err != nil {
+			continue
+		}
+
+		id := peeled.Id().String()
+		if existing, ok := tags[id]; ok && existing.annotated && !annotated {
+			continue
+		}
+		tags[id] = &describeTag{name: name, annotated: annotated}
+	}
+
+	return tags, nil
+}
+
+func matchDescribePatterns(opts operations.DescribeOptions, name string) bool {
+	for _, pattern := range opts.Exclude {
+		if operations.Wildmatch(pattern, name, 0) {
+			return false
+		}
+	}
+
+	if len(opts.Match) == 0 {
+		return true
+	}
+
+	for _, pattern := range opts.Match {
+		if operations.Wildmatch(pattern, name, 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// countCommits returns the number of commits reachable from the
+// revision that are not reachable from the base.
+func (self *repository) countCommits(rev, base *git.Oid) (int, error) {
+	walk, err := self.repo.Walk()
+	if err != nil {
+		return 0, err
+	}
+	defer walk.Free()
+
+	if err = walk.Push(rev); err != nil {
+		return 0, err
+	}
+	if err = walk.Hide(base); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = walk.Iterate(func(*git.Commit) bool {
+		count++
+		return true
+	})
+
+	return count, err
+}
+
+// isDirty returns true if the index or the working tree has changes
+// to tracked files, which is the check git describe uses.
+func (self *repository) isDirty() (bool, error) {
+	status, err := self.repo.StatusList(&git.StatusOptions{
+		Show: git.StatusShowIndexAndWorkdir,
+	})
+	if err != nil {
+		return false, err
+	}
+	defer status.Free()
+
+	count, err := status.EntryCount()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -11,16 +11,36 @@ import (
 
 	"gopkg.in/libgit2/git2go.v23"
 
+	"github.com/tychoish/gitgone/credentials"
+	"github.com/tychoish/gitgone/hooks"
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/signing"
 	"github.com/tychoish/gitgone/states"
 	"github.com/tychoish/grip"
 )
 
+// unsupported returns the error for operations and options that
+// libgit2 cannot provide.
+func unsupported(operation string) error {
+	return &operations.UnsupportedError{
+		Operation:      operation,
+		Implementation: "direct",
+	}
+}
+
 type repository struct {
 	path   string
 	exists bool
 	repo   *git.Repository
 	state  states.RepositoryState
 	err    error
+
+	recurseSubmodules bool
+	credentials       credentials.Provider
+	progress          operations.ProgressFunc
+	signer            signing.Signer
+	verifier          signing.Verifier
+	skipHooks         bool
 }
 
 func NewRepository(path string) *repository {
@@ -60,6 +80,10 @@ func (self *repository) Path() string {
 }
 
 func (self *repository) Branch() string {
+	if detached, err := self.repo.IsHeadDetached(); err == nil && detached {
+		return ""
+	}
+
 	ref, err := self.repo.Head()
 	if err != nil {
 		self.state = states.Degraded
@@ -109,18 +133,84 @@ func (self *repository) BranchExists(name string) bool {
 
 }
 
-func (self *repository) Clone(remote, branch string) (err error) {
+func (self *repository) Clone(remote, branch string) error {
+	return self.CloneWithOptions(remote, operations.CloneOptions{Branch: branch})
+}
+
+func (self *repository) CloneWithOptions(remote string, opts operations.CloneOptions) (err error) {
 	if _, err = os.Stat(self.path); err != nil && self.exists {
 		return fmt.Errorf("could not clone %s (%s) into %s, because repository exists",
-			remote, branch, self.path)
+			remote, opts.Branch, self.path)
 	}
 
-	// clone a repository from a remote
-	if err == nil {
-		self.exists = true
+	// libgit2 cannot negotiate shallow or partial clones, and
+	// has no support for alternates during a clone.
+	switch {
+	case opts.IsShallow():
+		return unsupported("shallow clones")
+	case opts.Filter != "":
+		return unsupported("partial clones")
+	case len(opts.References) > 0:
+		return unsupported("cloning with reference repositories")
+	case opts.SingleBranch && opts.Branch == "":
+		return unsupported("single branch clones of the default branch")
 	}
 
-	return err
+	cloneOpts := &git.CloneOptions{
+		CheckoutOpts:   self.checkoutOpts(git.CheckoutSafe),
+		FetchOptions:   self.fetchOptions(),
+		CheckoutBranch: opts.Branch,
+		Bare:           opts.Mirror,
+	}
+
+	if opts.NoCheckout {
+		cloneOpts.CheckoutOpts.Strategy = git.CheckoutNone
+	}
+
+	switch {
+	case opts.Mirror:
+		cloneOpts.RemoteCreateCallback = func(repo *git.Repository, name, url string) (*git.Remote, git.ErrorCode) {
+			remote, err := repo.Remotes.CreateWithFetchspec(name, url, "+refs/*:refs/*")
+			if err != nil {
+				return nil, git.ErrGeneric
+			}
+
+			config, err := repo.Config()
+			if err != nil {
+				return nil, git.ErrGeneric
+			}
+			defer config.Free()
+
+			if err = config.SetBool(fmt.Sprintf("remote.%s.mirror", name), true); err != nil {
+				return nil, git.ErrGeneric
+			}
+
+			return remote, git.ErrOk
+		}
+	case opts.SingleBranch:
+		cloneOpts.RemoteCreateCallback = func(repo *git.Repository, name, url string) (*git.Remote, git.ErrorCode) {
+			refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", opts.Branch, name, opts.Branch)
+			remote, err := repo.Remotes.CreateWithFetchspec(name, url, refspec)
+			if err != nil {
+				return nil, git.ErrGeneric
+			}
+
+			return remote, git.ErrOk
+		}
+	}
+
+	self.repo, err = git.Clone(remote, self.path, cloneOpts)
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+	self.exists = true
+
+	if opts.NoCheckout || opts.Mirror {
+		return nil
+	}
+
+	return self.updateRecursiveSubmodules()
 }
 
 func (self *repository) Checkout(ref string) error {
@@ -128,27 +218,61 @@ func (self *repository) Checkout(ref string) error {
 		return fmt.Errorf("cannot modify the working tree of this repository")
 	}
 
-	tree, err := self.getTree(ref)
+	commit, err := self.lookupRevCommit(ref)
 	if err != nil {
 		self.state = states.IncompleteOperation
 		return err
 	}
 
-	err = self.repo.CheckoutTree(tree, &git.CheckoutOpts{Strategy: git.CheckoutRecreateMissing})
+	previous := zeroID
+	if head, err := self.lookupRevCommit("HEAD"); err == nil {
+		previous = head.Id().String()
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	err = self.repo.CheckoutTree(tree, self.checkoutOpts(self.sparseStrategy(git.CheckoutSafe)))
 	if err != nil {
 		self.state = states.UnresolvedOperation
+		return err
 	}
 
-	return err
+	// like git, check out local branches by name, and detach HEAD
+	// for all other revisions.
+	if branch, err := self.repo.LookupBranch(ref, git.BranchLocal); err == nil {
+		err = self.repo.SetHead(branch.Reference.Name())
+	} else {
+		err = self.repo.SetHeadDetached(commit.Id())
+	}
+	if err != nil {
+		self.state = states.UnresolvedOperation
+		return err
+	}
+
+	if err = self.applySparseCheckout(); err != nil {
+		return err
+	}
+
+	if err = self.updateRecursiveSubmodules(); err != nil {
+		return err
+	}
+
+	// post-checkout cannot affect the outcome of the checkout,
+	// but like git, its failure is the result of the operation
+	return self.runHook(hooks.PostCheckout, nil, previous, commit.Id().String(), "1")
 }
 
 func (self *repository) getTree(name string) (tree *git.Tree, err error) {
-	ref, err := self.repo.References.Dwim(name)
+	commit, err := self.lookupRevCommit(name)
 	if err != nil {
 		return
 	}
 
-	tree, err = self.repo.LookupTree(ref.Target())
+	tree, err = commit.Tree()
 
 	return
 }
@@ -213,7 +337,7 @@ func (self *repository) Merge(baseRef string) error {
 		return err
 	}
 
-	err = self.repo.CheckoutIndex(index, &git.CheckoutOpts{Strategy: git.CheckoutRecreateMissing})
+	err = self.repo.CheckoutIndex(index, self.checkoutOpts(git.CheckoutRecreateMissing))
 	if err != nil {
 		self.state = states.UnresolvedOperation
 		return err
@@ -239,11 +363,12 @@ func (self *repository) Reset(ref string, hard bool) error {
 			return err
 		}
 
-		err = self.repo.CheckoutTree(tree, &git.CheckoutOpts{Strategy: git.CheckoutUseTheirs})
+		err = self.repo.CheckoutTree(tree, self.checkoutOpts(self.sparseStrategy(git.CheckoutUseTheirs)))
 		if err != nil {
 			self.state = states.FailedOperation
+			return err
 		}
-		return nil
+		return self.applySparseCheckout()
 	} else {
 		index, err := self.repo.Index()
 		if err != nil {
@@ -263,38 +388,23 @@ func (self *repository) Reset(ref string, hard bool) error {
 	}
 }
 
-func (self *repository) Fetch(remote string) error {
-	// TODO catche remotes in self
-	// TODO make legit git signature constructor
-
-	var remotes []*git.Remote
+// Deepen is not supported because libgit2 cannot fetch into shallow
+// repositories.
+func (self *repository) Deepen(commits int) error {
+	return unsupported("deepening shallow repositories")
+}
 
-	remoteNames, err := self.repo.Remotes.List()
+func (self *repository) Unshallow() error {
+	shallow, err := self.repo.IsShallow()
 	if err != nil {
-		return fmt.Errorf("no remotes defined")
-	}
-
-	for _, name := range remoteNames {
-		r, err := self.repo.Remotes.Lookup(name)
-		if err == nil {
-			remotes = append(remotes, r)
-		}
-	}
-
-	catcher := grip.NewCatcher()
-	if remote == "all" {
-		for _, remote := range remotes {
-			catcher.Add(remote.Fetch([]string{}, &git.FetchOptions{}, ""))
-		}
+		return err
 	}
 
-	for _, remote := range remotes {
-		if remote.Name() == remote.Name() {
-			catcher.Add(remote.Fetch([]string{}, &git.FetchOptions{}, ""))
-		}
+	if !shallow {
+		return nil
 	}
 
-	return catcher.Resolve()
+	return unsupported("unshallowing repositories")
 }
 
 func (self *repository) Pull(remote string, branch string) error {
@@ -307,10 +417,14 @@ func (self *repository) Pull(remote string, branch string) error {
 	err = self.Merge(strings.Join([]string{remote, branch}, "/"))
 	if err != nil {
 		self.state = states.UnresolvedOperation
+		return err
+	}
 
+	if err = self.applySparseCheckout(); err != nil {
+		return err
 	}
 
-	return err
+	return self.updateRecursiveSubmodules()
 }
 
 func (self *repository) PullRebase(remote string, branch string) error {
@@ -341,7 +455,7 @@ func (self *repository) CherryPick(commits ...string) error {
 		self.state = states.IncompleteOperation
 		return err
 	}
-	cpOpts.CheckoutOpts.Strategy = git.CheckoutRecreateMissing
+	cpOpts.CheckoutOpts = *self.checkoutOpts(git.CheckoutRecreateMissing)
 
 	for _, rc := range resolvedCommits {
 		err = self.repo.Cherrypick(rc, cpOpts)
@@ -405,19 +519,42 @@ func (self *repository) getCommitBasics() (signature *git.Signature, tree *git.T
 }
 
 func (self *repository) Commit(message string) error {
+	if err := self.runHook(hooks.PreCommit, nil); err != nil {
+		return err
+	}
+
 	sig, tree, err := self.getCommitBasics()
 	if err != nil {
 		self.state = states.UnresolvedOperation
 		return err
 	}
 
-	commit, err := self.repo.CreateCommit("HEAD", sig, sig, message, tree)
-	if err == nil {
+	var parents []*git.Commit
+	if head, err := self.lookupRevCommit("HEAD"); err == nil {
+		parents = append(parents, head)
+	}
+
+	message, err = self.commitMessageHook(message)
+	if err != nil {
+		return err
+	}
+
+	var commit *git.Oid
+	if self.signer != nil {
+		commit, err = self.createSignedCommit("commit", sig, sig, message, tree, parents...)
+	} else {
+		commit, err = self.repo.CreateCommit("HEAD", sig, sig, message, tree, parents...)
+	}
+
+	if err != nil {
 		self.state = states.FailedOperation
 		return err
 	} else {
 		grip.Debugf("created commit '%s' with message '%s' in repo '%s'",
 			commit, message, self.path)
+
+		// like git, ignore the result of post-commit hooks
+		_ = self.runHook(hooks.PostCommit, nil)
 		return nil
 	}
 }
@@ -438,6 +575,10 @@ func (self *repository) CommitAll(message string) error {
 }
 
 func (self *repository) Amend(message string) error {
+	if err := self.runHook(hooks.PreCommit, nil); err != nil {
+		return err
+	}
+
 	signature, tree, err := self.getCommitBasics()
 	if err != nil {
 		self.state = states.IncompleteOperation
@@ -456,132 +597,49 @@ func (self *repository) Amend(message string) error {
 		return err
 	}
 
-	newCommit, err := commit.Amend("HEAD", commit.Author(), signature, message, tree)
-	if err == nil {
-		self.state = states.IncompleteOperation
-		return err
-	} else {
-		grip.Debugf("amended commit '%s' to '%s' with message '%s' in repo '%s'",
-			commit, newCommit, message, self.path)
-		return nil
-	}
-}
-
-func (self *repository) AmendAll(message string) error {
-	err := self.Stage(self.path)
-
+	message, err = self.commitMessageHook(message)
 	if err != nil {
-		self.state = states.IncompleteOperation
 		return err
-	} else {
 	}
 
-	err = self.Amend(message)
-	if err != nil {
-		self.state = states.FailedOperation
-	}
-	return err
-
-}
+	var newCommit *git.Oid
+	if self.signer != nil {
+		var parents []*git.Commit
+		for i := uint(0); i < commit.ParentCount(); i++ {
+			parents = append(parents, commit.Parent(i))
+		}
 
-func (self *repository) Push(remote, branch string) error {
-	remoteRepo, err := self.repo.Remotes.Lookup(remote)
-	if err != nil {
-		self.state = states.IncompleteOperation
-		return err
+		newCommit, err = self.createSignedCommit("commit (amend)", commit.Author(), signature,
+			message, tree, parents...)
+	} else {
+		newCommit, err = commit.Amend("HEAD", commit.Author(), signature, message, tree)
 	}
 
-	refspec := fmt.Sprintf("refs/heads/%s", branch)
-
-	_, err = self.repo.References.Lookup(refspec)
 	if err != nil {
 		self.state = states.IncompleteOperation
 		return err
-	}
+	} else {
+		grip.Debugf("amended commit '%s' to '%s' with message '%s' in repo '%s'",
+			commit, newCommit, message, self.path)
 
-	err = remoteRepo.Push([]string{refspec}, nil)
-	if err != nil {
-		self.state = states.FailedOperation
+		_ = self.runHook(hooks.PostCommit, nil)
+		return nil
 	}
-
-	return err
 }
 
-func (self *repository) CreateTag(name, sha, message string, force bool) error {
-	oid, err := git.NewOid(sha)
-	if err != nil {
-		self.state = states.FailedOperation
-		return err
-	}
+func (self *repository) AmendAll(message string) error {
+	err := self.Stage(self.path)
 
-	commit, err := self.repo.LookupCommit(oid)
 	if err != nil {
 		self.state = states.IncompleteOperation
 		return err
-
-	}
-
-	signature, err := self.repo.DefaultSignature()
-	if err != nil {
-		self.state = states.FailedOperation
-		return err
-
-	}
-
-	if message == "" {
-		oid, err = self.repo.Tags.CreateLightweight(name, commit, force)
 	} else {
-		oid, err = self.repo.Tags.Create(name, commit, signature, message)
 	}
 
-	grip.Debugf("created tag '%s' of commit '%s' with hash '%s' in repo '%s'",
-		name, commit, self.path)
-
-	return err
-}
-
-func (self *repository) DeleteTag(name string) error {
-	tag, err := self.repo.References.Lookup(name)
-	if err != nil {
-		self.state = states.FailedOperation
-		return err
-	}
-
-	err = tag.Delete()
+	err = self.Amend(message)
 	if err != nil {
 		self.state = states.FailedOperation
-		return err
 	}
-
 	return err
-}
 
-func (self *repository) IsTagged(name, sha string, lightweight bool) bool {
-	oid, err := git.NewOid(sha)
-	if err != nil {
-		self.state = states.FailedOperation
-		return false
-	}
-
-	ref, err := self.repo.References.Lookup(fmt.Sprintf("refs/tags/%s", name))
-	if err != nil {
-		return false
-	}
-
-	if ref.Target() == oid {
-		if lightweight == true {
-			return true
-		} else {
-			tag, err := self.repo.LookupTag(ref.Target())
-			if err != nil {
-				return false
-			} else {
-				if tag.Message() != "" {
-					return false
-				}
-				return true
-			}
-		}
-	}
-	return false
 }
@@ -0,0 +1,98 @@
+package gitrect
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) Fetch(remote string) error {
+	_, err := self.FetchWithOptions(operations.FetchOptions{Remote: remote})
+	return err
+}
+
+func (self *repository) FetchWithOptions(opts operations.FetchOptions) (*operations.FetchResult, error) {
+	if opts.Depth > 0 {
+		return nil, unsupported("shallow fetches")
+	}
+
+	names := []string{opts.Remote}
+	if opts.Remote == "all" {
+		var err error
+		names, err = self.repo.Remotes.List()
+		if err != nil {
+			return nil, fmt.Errorf("no remotes defined")
+		}
+	}
+
+	result := &operations.FetchResult{}
+	for _, name := range names {
+		updates, err := self.fetchRemote(name, opts)
+		result.Updates = append(result.Updates, updates...)
+		if err != nil {
+			self.state = states.IncompleteOperation
+			return result, err
+		}
+	}
+
+	sort.Slice(result.Updates, func(i, j int) bool {
+		if result.Updates[i].Remote != result.Updates[j].Remote {
+			return result.Updates[i].Remote < result.Updates[j].Remote
+		}
+		return result.Updates[i].Ref < result.Updates[j].Ref
+	})
+
+	return result, nil
+}
+
+func (self *repository) fetchRemote(name string, opts operations.FetchOptions) ([]*operations.RefUpdate, error) {
+	remote, err := self.repo.Remotes.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("remote '%s' is not defined: %s", name, err)
+	}
+	defer remote.Free()
+
+	fetchOpts := self.fetchOptions()
+
+	if opts.Prune {
+		fetchOpts.Prune = git.FetchPruneOn
+	} else {
+		fetchOpts.Prune = git.FetchNoPrune
+	}
+
+	switch opts.Tags {
+	case operations.TagsAll:
+		fetchOpts.DownloadTags = git.DownloadTagsAll
+	case operations.TagsNone:
+		fetchOpts.DownloadTags = git.DownloadTagsNone
+	default:
+		fetchOpts.DownloadTags = git.DownloadTagsAuto
+	}
+
+	var updates []*operations.RefUpdate
+	fetchOpts.RemoteCallbacks.UpdateTipsCallback = func(refname string, a *git.Oid, b *git.Oid) git.ErrorCode {
+		update := &operations.RefUpdate{Remote: name, Ref: refname}
+		if a != nil && !a.IsZero() {
+			update.Old = a.String()
+		}
+		if b != nil && !b.IsZero() {
+			update.New = b.String()
+		}
+
+		if update.Old != "" && update.New != "" {
+			descendant, err := self.repo.DescendantOf(b, a)
+			update.Forced = err != nil || !descendant
+		}
+
+		updates = append(updates, update)
+		return git.ErrOk
+	}
+
+	err = remote.Fetch(opts.Refspecs, fetchOpts, "")
+
+	return updates, err
+}
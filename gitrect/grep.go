@@ -0,0 +1,110 @@
+package gitrect
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// binaryCheckSize is the amount of a blob that git examines for NUL
+// bytes to decide whether it is binary.
+const binaryCheckSize = 8000
+
+func (self *repository) Grep(rev, pattern string, opts operations.GrepOptions) ([]*operations.GrepMatch, error) {
+	if opts.FixedStrings {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*operations.GrepMatch
+	err = tree.Walk(func(root string, entry *git.TreeEntry) int {
+		if entry.Type != git.ObjectBlob {
+			return 0
+		}
+
+		name := path.Join(root, entry.Name)
+		if !matchPathspecs(opts.Pathspecs, name) {
+			return 0
+		}
+
+		blob, err := self.repo.LookupBlob(entry.Id)
+		if err != nil {
+			return -1
+		}
+
+		matches = append(matches, grepBlob(matcher, name, blob.Contents())...)
+		return 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func grepBlob(matcher *regexp.Regexp, name string, contents []byte) []*operations.GrepMatch {
+	head := contents
+	if len(head) > binaryCheckSize {
+		head = head[:binaryCheckSize]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+
+	var matches []*operations.GrepMatch
+	for idx, line := range bytes.Split(bytes.TrimSuffix(contents, []byte("\n")), []byte("\n")) {
+		if matcher.Match(line) {
+			matches = append(matches, &operations.GrepMatch{
+				Path: name,
+				Line: idx + 1,
+				Text: string(line),
+			})
+		}
+	}
+
+	return matches
+}
+
+// matchPathspecs returns true if there are no pathspecs, or if the
+// path is, or is within, one of them. Pathspecs may contain glob
+// wildcards, which match across directories as they do in git.
+func matchPathspecs(pathspecs []string, name string) bool {
+	if len(pathspecs) == 0 {
+		return true
+	}
+
+	for _, spec := range pathspecs {
+		spec = strings.TrimSuffix(strings.TrimPrefix(spec, "./"), "/")
+		if spec == "" || spec == "." || name == spec || strings.HasPrefix(name, spec+"/") {
+			return true
+		}
+
+		if operations.Wildmatch(spec, name, 0) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package gitrect
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/hooks"
+)
+
+// zeroID is the object id that hooks receive for refs that do not
+// exist.
+const zeroID = "0000000000000000000000000000000000000000"
+
+// SetSkipHooks disables the repository's hook scripts for all
+// operations.
+func (self *repository) SetSkipHooks(skip bool) {
+	self.skipHooks = skip
+}
+
+// hookRunner returns a runner for the hooks directory that git uses:
+// core.hooksPath, relative to the top of the working tree, or the
+// hooks directory in the git directory.
+func (self *repository) hookRunner() *hooks.Runner {
+	workdir := strings.TrimSuffix(self.repo.Workdir(), "/")
+	if self.repo.IsBare() {
+		workdir = strings.TrimSuffix(self.repo.Path(), "/")
+	}
+
+	runner := &hooks.Runner{
+		Dir:     filepath.Join(self.repo.Path(), "hooks"),
+		WorkDir: workdir,
+	}
+
+	if dir, err := self.Config().Get("core.hooksPath"); err == nil && dir != "" {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workdir, dir)
+		}
+		runner.Dir = dir
+	}
+
+	return runner
+}
+
+// runHook runs a hook script, since libgit2 never runs hooks itself.
+func (self *repository) runHook(hook hooks.Type, stdin []byte, args ...string) error {
+	if self.skipHooks {
+		return nil
+	}
+
+	return self.hookRunner().Run(hook, stdin, args...)
+}
+
+func (self *repository) commitMessageHook(message string) (string, error) {
+	if self.skipHooks {
+		return message, nil
+	}
+
+	return self.hookRunner().CommitMessage(message)
+}
@@ -0,0 +1,208 @@
+package gitrect
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// IsIgnored reports whether each path is ignored, and the rule that
+// matched it. libgit2 cannot report the rule, so the ignore files are
+// read in the same order of precedence that git uses, and the rule
+// that matches decides whether the path is ignored. Like "git
+// check-ignore", tracked files are never ignored.
+func (self *repository) IsIgnored(paths ...string) ([]*operations.IgnoreResult, error) {
+	tracked, err := self.trackedPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*operations.IgnoreResult, 0, len(paths))
+	for _, name := range paths {
+		name = filepath.ToSlash(filepath.Clean(name))
+		result := &operations.IgnoreResult{Path: name}
+		results = append(results, result)
+
+		if tracked[name] {
+			continue
+		}
+
+		rules, err := self.ignoreRules(path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+
+		// directory only patterns need to know which paths are
+		// directories in the working tree.
+		match := name
+		if info, err := os.Stat(filepath.Join(self.repo.Workdir(), filepath.FromSlash(name))); err == nil && info.IsDir() {
+			match += "/"
+		}
+
+		if rule := operations.MatchIgnoreRules(rules, match); rule != nil {
+			result.Ignored = !rule.IsNegated()
+			result.Source = rule.Source
+			result.Line = rule.Line
+			result.Pattern = rule.Pattern
+		}
+	}
+
+	return results, nil
+}
+
+func (self *repository) trackedPaths() (map[string]bool, error) {
+	tracked := map[string]bool{}
+	if self.repo.IsBare() {
+		return tracked, nil
+	}
+
+	index, err := self.repo.Index()
+	if err != nil {
+		return nil, err
+	}
+	defer index.Free()
+
+	for i := uint(0); i < index.EntryCount(); i++ {
+		entry, err := index.EntryByIndex(i)
+		if err != nil {
+			return nil, err
+		}
+		tracked[entry.Path] = true
+	}
+
+	return tracked, nil
+}
+
+// ignoreRules returns the rules that apply to files in a directory,
+// in increasing order of precedence: core.excludesFile, info/exclude,
+// and the .gitignore files from the top of the working tree down to
+// the directory.
+func (self *repository) ignoreRules(dir string) ([]*operations.IgnoreRule, error) {
+	var rules []*operations.IgnoreRule
+	workdir := self.repo.Workdir()
+
+	excludes, err := self.Config().Get("core.excludesFile")
+	if err != nil {
+		excludes = filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "git", "ignore")
+		if os.Getenv("XDG_CONFIG_HOME") == "" {
+			excludes = "~/.config/git/ignore"
+		}
+	}
+	if strings.HasPrefix(excludes, "~/") {
+		if u, err := user.Current(); err == nil {
+			excludes = filepath.Join(u.HomeDir, excludes[2:])
+		}
+	}
+
+	sources := []string{excludes, filepath.Join(self.repo.Path(), "info", "exclude")}
+	dirs := []string{"", ""}
+
+	if !self.repo.IsBare() {
+		var parts []string
+		if dir != "." {
+			parts = strings.Split(dir, "/")
+		}
+
+		for i := 0; i <= len(parts); i++ {
+			sub := strings.Join(parts[:i], "/")
+			sources = append(sources, filepath.Join(workdir, filepath.FromSlash(sub), ".gitignore"))
+			dirs = append(dirs, sub)
+		}
+	}
+
+	for idx, source := range sources {
+		contents, err := ioutil.ReadFile(source)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		// like git, name sources in the repository relative to
+		// the top of the working tree
+		name := source
+		if workdir != "" {
+			if rel, err := filepath.Rel(workdir, source); err == nil && !strings.HasPrefix(rel, "..") {
+				name = filepath.ToSlash(rel)
+			}
+		}
+
+		rules = append(rules, operations.ParseIgnoreRules(name, dirs[idx], contents)...)
+	}
+
+	return rules, nil
+}
+
+// AddIgnoreRule adds a rule to the .gitignore file at the top of the
+// working tree, or, for rules that should not be shared with other
+// clones, to the repository's info/exclude file.
+func (self *repository) AddIgnoreRule(rule string, local bool) error {
+	filename := filepath.Join(self.repo.Workdir(), ".gitignore")
+	if local || self.repo.IsBare() {
+		filename = filepath.Join(self.repo.Path(), "info", "exclude")
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return err
+		}
+	}
+
+	return operations.AppendIgnoreRule(filename, rule)
+}
+
+// Attributes returns the named gitattributes attributes of a path,
+// in order, or all of the attributes that apply to it when no names
+// are given. libgit2 0.23's attribute API is not available through
+// git2go, so the gitattributes files are read in the order of
+// precedence that git uses: core.attributesFile, the .gitattributes
+// files from the top of the working tree down to the path, and
+// info/attributes.
+func (self *repository) Attributes(name string, names ...string) ([]*operations.Attribute, error) {
+	name = filepath.ToSlash(filepath.Clean(name))
+
+	var sources, dirs []string
+	if file, err := self.Config().Get("core.attributesFile"); err == nil {
+		if strings.HasPrefix(file, "~/") {
+			if u, err := user.Current(); err == nil {
+				file = filepath.Join(u.HomeDir, file[2:])
+			}
+		}
+		sources = append(sources, file)
+		dirs = append(dirs, "")
+	}
+
+	if !self.repo.IsBare() {
+		var parts []string
+		if dir := path.Dir(name); dir != "." {
+			parts = strings.Split(dir, "/")
+		}
+
+		for i := 0; i <= len(parts); i++ {
+			sub := strings.Join(parts[:i], "/")
+			sources = append(sources, filepath.Join(self.repo.Workdir(), filepath.FromSlash(sub), ".gitattributes"))
+			dirs = append(dirs, sub)
+		}
+	}
+
+	sources = append(sources, filepath.Join(self.repo.Path(), "info", "attributes"))
+	dirs = append(dirs, "")
+
+	var rules []*operations.AttributeRule
+	for idx, source := range sources {
+		contents, err := ioutil.ReadFile(source)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		rules = append(rules, operations.ParseAttributeRules(dirs[idx], contents)...)
+	}
+
+	return operations.ResolveAttributes(rules, name, names...), nil
+}
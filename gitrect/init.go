@@ -0,0 +1,99 @@
+package gitrect
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) Init(bare bool, initialBranch, template string) error {
+	if self.exists {
+		return fmt.Errorf("cannot initialize repository at %s, because repository exists", self.path)
+	}
+
+	err := os.MkdirAll(self.path, 0755)
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	repo, err := git.InitRepository(self.path, bare)
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	self.repo = repo
+	self.path = repo.Path()
+	self.exists = true
+	self.err = nil
+	self.state = states.New
+
+	if template != "" {
+		err = copyTemplate(template, repo.Path())
+		if err != nil {
+			self.state = states.IncompleteOperation
+			return err
+		}
+	}
+
+	if initialBranch != "" {
+		_, err = repo.References.CreateSymbolic("HEAD", "refs/heads/"+initialBranch, true,
+			fmt.Sprintf("initialized with branch %s", initialBranch))
+		if err != nil {
+			self.state = states.IncompleteOperation
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyTemplate copies the contents of a template directory into a
+// new repository's git directory without overwriting any of the
+// files created by libgit2, as "git init --template" does.
+func copyTemplate(template, gitDir string) error {
+	return filepath.Walk(template, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(template, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(gitDir, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+
+		if _, err = os.Stat(target); err == nil {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(dst, src)
+		if err != nil {
+			dst.Close()
+			return err
+		}
+
+		return dst.Close()
+	})
+}
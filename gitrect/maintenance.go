@@ -0,0 +1,343 @@
+package gitrect
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// Maintenance reports object statistics and checks the object
+// store. libgit2 0.23 cannot collect garbage, repack, prune or write
+// commit graphs.
+func (self *repository) Maintenance(opts operations.MaintenanceOptions) (*operations.MaintenanceResult, error) {
+	switch {
+	case opts.GC != operations.GCNone:
+		return nil, unsupported("garbage collection")
+	case opts.Repack:
+		return nil, unsupported("repacking")
+	case opts.Prune:
+		return nil, unsupported("pruning")
+	case opts.CommitGraph:
+		return nil, unsupported("writing commit graphs")
+	}
+
+	result := &operations.MaintenanceResult{}
+
+	before, err := self.objectStats()
+	if err != nil {
+		return nil, err
+	}
+	result.Before = before
+
+	if opts.Fsck {
+		result.Findings, err = self.fsck()
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.After, err = self.objectStats()
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// objectStats counts objects by reading the object directory, and
+// the object count from the header of each pack index.
+func (self *repository) objectStats() (operations.ObjectStats, error) {
+	stats := operations.ObjectStats{}
+	objects := filepath.Join(self.repo.Path(), "objects")
+
+	dirs, err := ioutil.ReadDir(objects)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || len(dir.Name()) != 2 {
+			continue
+		}
+
+		files, err := ioutil.ReadDir(filepath.Join(objects, dir.Name()))
+		if err != nil {
+			return stats, err
+		}
+
+		for _, file := range files {
+			stats.LooseObjects++
+			stats.LooseSize += file.Size()
+		}
+	}
+
+	packs, err := ioutil.ReadDir(filepath.Join(objects, "pack"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return stats, err
+	}
+
+	for _, pack := range packs {
+		switch filepath.Ext(pack.Name()) {
+		case ".pack":
+			stats.Packs++
+			stats.PackSize += pack.Size()
+		case ".idx":
+			stats.PackSize += pack.Size()
+
+			count, err := packIndexCount(filepath.Join(objects, "pack", pack.Name()))
+			if err != nil {
+				return stats, err
+			}
+			stats.PackedObjects += count
+		}
+	}
+
+	return stats, nil
+}
+
+// packIndexCount reads the number of objects in a version 2 pack
+// index, which is the last entry of its fan-out table.
+func packIndexCount(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 8+256*4)
+	if _, err = io.ReadFull(file, header); err != nil {
+		return 0, err
+	}
+
+	if string(header[:4]) != "\377tOc" || binary.BigEndian.Uint32(header[4:8]) != 2 {
+		return 0, fmt.Errorf("unsupported pack index %s", path)
+	}
+
+	return int(binary.BigEndian.Uint32(header[len(header)-4:])), nil
+}
+
+// fsck reads every object in the object store, reporting objects
+// that cannot be read or do not match their ids, objects referenced
+// by other objects or refs that are missing, and dangling objects.
+func (self *repository) fsck() ([]*operations.FsckFinding, error) {
+	odb, err := self.repo.Odb()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []*git.Oid
+	err = odb.ForEach(func(id *git.Oid) error {
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*operations.FsckFinding
+	present := map[string]git.ObjectType{}
+	referenced := map[string]git.ObjectType{}
+	roots, err := self.fsckRoots()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		obj, err := odb.Read(id)
+		if err != nil {
+			findings = append(findings, &operations.FsckFinding{
+				Kind:    operations.FsckCorrupt,
+				Object:  id.String(),
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		kind := obj.Type()
+		hash, err := odb.Hash(obj.Data(), kind)
+		obj.Free()
+		if err != nil || !hash.Equal(id) {
+			findings = append(findings, &operations.FsckFinding{
+				Kind:       operations.FsckCorrupt,
+				ObjectType: kind.String(),
+				Object:     id.String(),
+				Message:    fmt.Sprintf("hash mismatch %s", id),
+			})
+			continue
+		}
+		present[id.String()] = kind
+
+		switch kind {
+		case git.ObjectCommit:
+			commit, err := self.repo.LookupCommit(id)
+			if err != nil {
+				continue
+			}
+			referenced[commit.TreeId().String()] = git.ObjectTree
+			for i := uint(0); i < commit.ParentCount(); i++ {
+				referenced[commit.ParentId(i).String()] = git.ObjectCommit
+			}
+		case git.ObjectTree:
+			tree, err := self.repo.LookupTree(id)
+			if err != nil {
+				continue
+			}
+			for i := uint64(0); i < tree.EntryCount(); i++ {
+				entry := tree.EntryByIndex(i)
+				if entry.Type == git.ObjectCommit {
+					// submodules refer to commits
+					// in other repositories
+					continue
+				}
+				referenced[entry.Id.String()] = entry.Type
+			}
+		case git.ObjectTag:
+			tag, err := self.repo.LookupTag(id)
+			if err != nil {
+				continue
+			}
+			referenced[tag.TargetId().String()] = tag.TargetType()
+		}
+	}
+
+	for id, kind := range roots {
+		if _, ok := referenced[id]; !ok {
+			referenced[id] = kind
+		}
+	}
+
+	for id, kind := range referenced {
+		if _, ok := present[id]; !ok && !isCorrupt(findings, id) {
+			findings = append(findings, &operations.FsckFinding{
+				Kind:       operations.FsckMissing,
+				ObjectType: objectTypeName(kind),
+				Object:     id,
+				Message:    fmt.Sprintf("missing %s %s", objectTypeName(kind), id),
+			})
+		}
+	}
+
+	for id, kind := range present {
+		if _, ok := referenced[id]; !ok {
+			findings = append(findings, &operations.FsckFinding{
+				Kind:       operations.FsckDangling,
+				ObjectType: kind.String(),
+				Object:     id,
+				Message:    fmt.Sprintf("dangling %s %s", kind, id),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// objectTypeName returns the name git uses for an object type, or
+// "object" for refs whose target type is unknown.
+func objectTypeName(kind git.ObjectType) string {
+	if kind == git.ObjectAny {
+		return "object"
+	}
+
+	return kind.String()
+}
+
+func isCorrupt(findings []*operations.FsckFinding, id string) bool {
+	for _, finding := range findings {
+		if finding.Kind == operations.FsckCorrupt && finding.Object == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fsckRoots returns the objects that refs, HEAD, their reflogs, and
+// the index refer to, which are reachable even when no object refers
+// to them. As with git fsck, objects that only a reflog refers to are
+// not dangling.
+func (self *repository) fsckRoots() (map[string]git.ObjectType, error) {
+	roots := map[string]git.ObjectType{}
+	names := []string{"HEAD"}
+
+	iter, err := self.repo.NewReferenceIterator()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Free()
+
+	for {
+		ref, err := iter.Next()
+		if err != nil {
+			if git.IsErrorCode(err, git.ErrIterOver) {
+				break
+			}
+			return nil, err
+		}
+
+		names = append(names, ref.Name())
+		if ref.Type() == git.ReferenceOid {
+			roots[ref.Target().String()] = git.ObjectAny
+		}
+	}
+
+	if head, err := self.repo.Head(); err == nil {
+		roots[head.Target().String()] = git.ObjectCommit
+	}
+
+	for _, name := range names {
+		if err = self.addReflogRoots(roots, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if !self.repo.IsBare() {
+		index, err := self.repo.Index()
+		if err != nil {
+			return nil, err
+		}
+		defer index.Free()
+
+		for i := uint(0); i < index.EntryCount(); i++ {
+			entry, err := index.EntryByIndex(i)
+			if err != nil {
+				return nil, err
+			}
+			roots[entry.Id.String()] = git.ObjectBlob
+		}
+	}
+
+	return roots, nil
+}
+
+// addReflogRoots adds the objects in the reflog of a ref to the roots.
+func (self *repository) addReflogRoots(roots map[string]git.ObjectType, name string) error {
+	reflog, err := self.repo.ReadReflog(name)
+	if err != nil {
+		return fmt.Errorf("could not read reflog of %s: %s", name, err)
+	}
+	defer reflog.Free()
+
+	for i := uint(0); i < reflog.EntryCount(); i++ {
+		entry := reflog.EntryByIndex(i)
+		for _, id := range []*git.Oid{entry.Old, entry.New} {
+			if id == nil || id.IsZero() {
+				continue
+			}
+			if _, ok := roots[id.String()]; !ok {
+				roots[id.String()] = git.ObjectAny
+			}
+		}
+	}
+
+	return nil
+}
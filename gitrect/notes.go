@@ -0,0 +1,103 @@
+package gitrect
+
+import (
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) AddNote(ref, rev, message string, force bool) error {
+	obj, err := self.repo.RevparseSingle(rev)
+	if err != nil {
+		return err
+	}
+
+	signature, err := self.repo.DefaultSignature()
+	if err != nil {
+		return err
+	}
+
+	// git terminates notes with a newline, libgit2 does not.
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	_, err = self.repo.Notes.Create(operations.ExpandNotesRef(ref), signature, signature,
+		obj.Id(), message, force)
+	return err
+}
+
+func (self *repository) ReadNote(ref, rev string) (string, error) {
+	obj, err := self.repo.RevparseSingle(rev)
+	if err != nil {
+		return "", err
+	}
+
+	note, err := self.repo.Notes.Read(operations.ExpandNotesRef(ref), obj.Id())
+	if err != nil {
+		if isNotFound(err) {
+			return "", operations.ErrNoteNotFound
+		}
+		return "", err
+	}
+	defer note.Free()
+
+	return strings.TrimSuffix(note.Message(), "\n"), nil
+}
+
+func (self *repository) RemoveNote(ref, rev string) error {
+	obj, err := self.repo.RevparseSingle(rev)
+	if err != nil {
+		return err
+	}
+
+	signature, err := self.repo.DefaultSignature()
+	if err != nil {
+		return err
+	}
+
+	err = self.repo.Notes.Remove(operations.ExpandNotesRef(ref), signature, signature, obj.Id())
+	if isNotFound(err) {
+		return operations.ErrNoteNotFound
+	}
+
+	return err
+}
+
+func (self *repository) ListNotes(ref string) ([]*operations.Note, error) {
+	iter, err := self.repo.NewNoteIterator(operations.ExpandNotesRef(ref))
+	if err != nil {
+		if isNotFound(err) {
+			// the notes ref does not exist yet.
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer iter.Free()
+
+	var notes []*operations.Note
+	for {
+		noteID, objectID, err := iter.Next()
+		if err != nil {
+			if git.IsErrorCode(err, git.ErrIterOver) {
+				break
+			}
+			return nil, err
+		}
+
+		blob, err := self.repo.LookupBlob(noteID)
+		if err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, &operations.Note{
+			Object:  objectID.String(),
+			Note:    noteID.String(),
+			Message: strings.TrimSuffix(string(blob.Contents()), "\n"),
+		})
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,199 @@
+package gitrect
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// FormatPatch writes each non-merge commit in the range, oldest
+// first, in the format of "git format-patch", without a diffstat.
+func (self *repository) FormatPatch(commits string) ([]*operations.Patch, error) {
+	until, since := operations.SplitPatchRange(commits)
+
+	walk, err := self.repo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+
+	walk.Sorting(git.SortTopological | git.SortReverse)
+
+	for rev, hide := range map[string]bool{until: false, since: true} {
+		if rev == "" {
+			continue
+		}
+
+		commit, err := self.lookupRevCommit(rev)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %s: %s", rev, err)
+		}
+
+		if hide {
+			err = walk.Hide(commit.Id())
+		} else {
+			err = walk.Push(commit.Id())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var selected []*git.Commit
+	err = walk.Iterate(func(commit *git.Commit) bool {
+		if commit.ParentCount() <= 1 {
+			selected = append(selected, commit)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	patches := make([]*operations.Patch, 0, len(selected))
+	for idx, commit := range selected {
+		diff, err := self.commitDiff(commit)
+		if err != nil {
+			return nil, fmt.Errorf("could not diff %s: %s", commit.Id(), err)
+		}
+
+		prefix := "[PATCH]"
+		if len(selected) > 1 {
+			prefix = fmt.Sprintf("[PATCH %d/%d]", idx+1, len(selected))
+		}
+
+		patches = append(patches, formatPatch(commit, prefix, diff))
+	}
+
+	return patches, nil
+}
+
+// commitDiff returns the diff between a commit and its parent, or the
+// entire tree for root commits.
+func (self *repository) commitDiff(commit *git.Commit) ([]byte, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *git.Tree
+	if commit.ParentCount() > 0 {
+		parentTree, err = commit.Parent(0).Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	opts, err := git.DefaultDiffOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := self.repo.DiffTreeToTree(parentTree, tree, &opts)
+	if err != nil {
+		return nil, err
+	}
+	defer diff.Free()
+
+	num, err := diff.NumDeltas()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	for i := 0; i < num; i++ {
+		patch, err := diff.Patch(i)
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := patch.String()
+		patch.Free()
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString(text)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func formatPatch(commit *git.Commit, prefix string, diff []byte) *operations.Patch {
+	author := commit.Author()
+	subject, body := splitCommitMessage(commit.Message())
+	ident := fmt.Sprintf("%s <%s>", author.Name, author.Email)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From %s Mon Sep 17 00:00:00 2001\n", commit.Id())
+	fmt.Fprintf(buf, "From: %s <%s>\n", encodeHeader(author.Name), author.Email)
+	fmt.Fprintf(buf, "Date: %s\n", author.When.Format("Mon, 2 Jan 2006 15:04:05 -0700"))
+	fmt.Fprintf(buf, "Subject: %s\n", encodeHeader(prefix+" "+subject))
+	if !isASCII(subject+body) || !isASCII(author.Name) {
+		buf.WriteString("MIME-Version: 1.0\n")
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\n")
+		buf.WriteString("Content-Transfer-Encoding: 8bit\n")
+	}
+	buf.WriteString("\n")
+	buf.WriteString(body)
+	buf.WriteString("---\n\n")
+	buf.Write(diff)
+	buf.WriteString("\n")
+
+	return &operations.Patch{
+		Commit:   commit.Id().String(),
+		Author:   ident,
+		Date:     author.When,
+		Subject:  subject,
+		Contents: buf.Bytes(),
+	}
+}
+
+// splitCommitMessage returns the summary of a commit message, the
+// first paragraph joined into a single line, and the remaining body
+// with a trailing newline when present.
+func splitCommitMessage(message string) (string, string) {
+	message = strings.TrimSpace(message)
+	parts := strings.SplitN(message, "\n\n", 2)
+	subject := strings.Join(strings.Fields(parts[0]), " ")
+
+	if len(parts) == 1 {
+		return subject, ""
+	}
+
+	return subject, strings.TrimSpace(parts[1]) + "\n"
+}
+
+func encodeHeader(value string) string {
+	if isASCII(value) {
+		return value
+	}
+
+	return mime.QEncoding.Encode("UTF-8", value)
+}
+
+func isASCII(value string) bool {
+	for _, r := range value {
+		if r > 127 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// libgit2 0.23 cannot apply patches.
+
+func (self *repository) ApplyPatch(_ io.Reader, _, _, _ bool) error {
+	return unsupported("applying patches")
+}
+
+func (self *repository) ApplyMailbox(_ io.Reader) error {
+	return unsupported("applying mailboxes")
+}
@@ -0,0 +1,74 @@
+package gitrect
+
+import (
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) SetProgress(progress operations.ProgressFunc) {
+	self.progress = progress
+}
+
+// addProgressCallbacks reports transfer progress from libgit2's
+// fetch and push callbacks. A single fetch callback reports both
+// received and indexed objects, so the stage changes once all
+// objects have arrived.
+func (self *repository) addProgressCallbacks(callbacks *git.RemoteCallbacks) {
+	if self.progress == nil {
+		return
+	}
+
+	callbacks.TransferProgressCallback = func(stats git.TransferProgress) git.ErrorCode {
+		if stats.ReceivedObjects < stats.TotalObjects {
+			self.progress(operations.Progress{
+				Stage:   operations.ProgressReceiving,
+				Current: uint64(stats.ReceivedObjects),
+				Total:   uint64(stats.TotalObjects),
+				Bytes:   uint64(stats.ReceivedBytes),
+			})
+		} else {
+			self.progress(operations.Progress{
+				Stage:   operations.ProgressIndexing,
+				Current: uint64(stats.IndexedDeltas),
+				Total:   uint64(stats.TotalDeltas),
+				Bytes:   uint64(stats.ReceivedBytes),
+			})
+		}
+
+		return git.ErrOk
+	}
+
+	callbacks.PushTransferProgressCallback = func(current, total uint32, bytes uint) git.ErrorCode {
+		self.progress(operations.Progress{
+			Stage:   operations.ProgressSending,
+			Current: uint64(current),
+			Total:   uint64(total),
+			Bytes:   uint64(bytes),
+		})
+
+		return git.ErrOk
+	}
+}
+
+// checkoutOpts returns options for operations that update the
+// working tree, reporting checkout progress when the repository has
+// a progress function.
+func (self *repository) checkoutOpts(strategy git.CheckoutStrategy) *git.CheckoutOpts {
+	opts := &git.CheckoutOpts{Strategy: strategy}
+
+	if self.progress != nil {
+		opts.ProgressCallback = func(path string, completed, total uint) git.ErrorCode {
+			self.progress(operations.Progress{
+				Stage:   operations.ProgressCheckout,
+				Current: uint64(completed),
+				Total:   uint64(total),
+				Path:    path,
+			})
+
+			return git.ErrOk
+		}
+	}
+
+	return opts
+}
@@ -0,0 +1,286 @@
+package gitrect
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/hooks"
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) Push(remote, branch string) error {
+	_, err := self.PushWithOptions(operations.PushOptions{
+		Remote:   remote,
+		Refspecs: []string{branch},
+	})
+
+	return err
+}
+
+// pushSpec is a single resolved refspec, where an empty source means
+// the destination is deleted.
+type pushSpec struct {
+	source      string
+	destination string
+	force       bool
+}
+
+func (s pushSpec) String() string {
+	if s.force {
+		return fmt.Sprintf("+%s:%s", s.source, s.destination)
+	}
+	return fmt.Sprintf("%s:%s", s.source, s.destination)
+}
+
+func (self *repository) PushWithOptions(opts operations.PushOptions) (*operations.PushResult, error) {
+	if opts.Atomic {
+		return nil, unsupported("atomic pushes")
+	}
+
+	remote, err := self.repo.Remotes.Lookup(opts.Remote)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return nil, err
+	}
+	defer remote.Free()
+
+	specs, err := self.resolvePushSpecs(opts)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return nil, err
+	}
+
+	remoteRefs, err := self.listRemoteRefs(remote)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return nil, err
+	}
+
+	result := &operations.PushResult{}
+	updates := make(map[string]*operations.PushUpdate)
+	var refspecs []string
+	hookInput := &bytes.Buffer{}
+
+	for _, spec := range specs {
+		update := &operations.PushUpdate{
+			Source:      spec.source,
+			Destination: spec.destination,
+			Old:         remoteRefs[spec.destination],
+		}
+		result.Updates = append(result.Updates, update)
+
+		if spec.source != "" {
+			ref, err := self.repo.References.Lookup(spec.source)
+			if err != nil {
+				self.state = states.IncompleteOperation
+				return nil, err
+			}
+			update.New = ref.Target().String()
+		}
+
+		// libgit2 has no notion of leases, so check the
+		// expected values against the remote immediately
+		// before pushing, and force the refs that match.
+		if expected, ok := leaseFor(opts.Leases, spec.destination); ok {
+			if expected != update.Old {
+				update.Status = operations.PushRejected
+				update.Reason = "stale info"
+				continue
+			}
+			spec.force = true
+		}
+
+		if update.Old != "" && update.Old == update.New {
+			update.Status = operations.PushUpToDate
+			continue
+		}
+
+		updates[spec.destination] = update
+		refspecs = append(refspecs, spec.String())
+		fmt.Fprintf(hookInput, "%s %s %s %s\n", pushHookRef(spec.source), pushHookID(update.New),
+			spec.destination, pushHookID(update.Old))
+	}
+
+	if len(refspecs) > 0 {
+		err = self.runHook(hooks.PrePush, hookInput.Bytes(), opts.Remote, remote.Url())
+		if err != nil {
+			self.state = states.FailedOperation
+			return result, err
+		}
+
+		pushOpts := self.pushOptions()
+		pushOpts.RemoteCallbacks.PushUpdateReferenceCallback = func(refname, status string) git.ErrorCode {
+			if update, ok := updates[refname]; ok && status != "" {
+				update.Status = operations.PushRejected
+				update.Reason = status
+			}
+			return git.ErrOk
+		}
+
+		err = remote.Push(refspecs, pushOpts)
+		if err != nil {
+			self.state = states.FailedOperation
+			return result, err
+		}
+	}
+
+	for _, update := range updates {
+		if update.Status == operations.PushRejected {
+			continue
+		}
+		update.Status = self.pushStatus(update)
+	}
+
+	if err = result.Error(); err != nil {
+		self.state = states.PartialOperation
+	}
+
+	return result, err
+}
+
+// pushHookRef and pushHookID format the refs and object ids of an
+// update in the form git passes them to pre-push hooks, where deleted
+// and missing refs are "(delete)" and the zero id.
+func pushHookRef(ref string) string {
+	if ref == "" {
+		return "(delete)"
+	}
+	return ref
+}
+
+func pushHookID(id string) string {
+	if id == "" {
+		return zeroID
+	}
+	return id
+}
+
+// pushStatus classifies an accepted update by comparing the old and
+// new values of the remote ref.
+func (self *repository) pushStatus(update *operations.PushUpdate) operations.PushStatus {
+	switch {
+	case update.New == "":
+		return operations.PushDeleted
+	case update.Old == "":
+		return operations.PushCreated
+	}
+
+	oldID, err := git.NewOid(update.Old)
+	if err != nil {
+		return operations.PushForced
+	}
+	newID, err := git.NewOid(update.New)
+	if err != nil {
+		return operations.PushForced
+	}
+
+	descendant, err := self.repo.DescendantOf(newID, oldID)
+	if err != nil || !descendant {
+		return operations.PushForced
+	}
+
+	return operations.PushUpdated
+}
+
+// resolvePushSpecs expands the refspecs in the options into full
+// source and destination ref names, adding every local tag when
+// pushing tags.
+func (self *repository) resolvePushSpecs(opts operations.PushOptions) ([]pushSpec, error) {
+	var specs []pushSpec
+
+	for _, refspec := range opts.Refspecs {
+		force := opts.Force || strings.HasPrefix(refspec, "+")
+		refspec = strings.TrimPrefix(refspec, "+")
+
+		if opts.Delete {
+			specs = append(specs, pushSpec{destination: qualifyRef(refspec)})
+			continue
+		}
+
+		parts := strings.SplitN(refspec, ":", 2)
+
+		ref, err := self.repo.References.Dwim(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve '%s' to push: %s", parts[0], err)
+		}
+
+		// symbolic refs, like HEAD, push the branch they point
+		// to, which has a target to compare with the remote.
+		source, err := ref.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve '%s' to push: %s", parts[0], err)
+		}
+
+		spec := pushSpec{source: source.Name(), destination: source.Name(), force: force}
+		if len(parts) == 1 && !strings.HasPrefix(spec.destination, "refs/") {
+			return nil, fmt.Errorf("cannot push '%s' without a destination, because it is not a branch", parts[0])
+		}
+		if len(parts) == 2 {
+			if parts[1] == "" {
+				return nil, fmt.Errorf("refspec '%s' has no destination", refspec)
+			}
+			spec.destination = qualifyRef(parts[1])
+		}
+
+		specs = append(specs, spec)
+	}
+
+	if opts.Tags {
+		tags, err := self.repo.Tags.List()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tags {
+			name := "refs/tags/" + tag
+			specs = append(specs, pushSpec{source: name, destination: name, force: opts.Force})
+		}
+	}
+
+	return specs, nil
+}
+
+// listRemoteRefs returns the object names of the refs on a remote.
+func (self *repository) listRemoteRefs(remote *git.Remote) (map[string]string, error) {
+	callbacks := self.remoteCallbacks()
+	err := remote.Connect(git.ConnectDirectionPush, &callbacks)
+	if err != nil {
+		return nil, err
+	}
+	defer remote.Disconnect()
+
+	heads, err := remote.Ls()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]string)
+	for _, head := range heads {
+		refs[head.Name] = head.Id.String()
+	}
+
+	return refs, nil
+}
+
+func leaseFor(leases []operations.Lease, ref string) (string, bool) {
+	for _, lease := range leases {
+		if qualifyRef(lease.Ref) == ref {
+			return lease.Expected, true
+		}
+	}
+
+	return "", false
+}
+
+// qualifyRef treats names without a "refs/" prefix as branch names.
+func qualifyRef(name string) string {
+	if strings.HasPrefix(name, "refs/") {
+		return name
+	}
+
+	return "refs/heads/" + name
+}
@@ -0,0 +1,161 @@
+package gitrect
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// Reflog returns the entries in the reflog of a ref, most recent
+// first, so that the entry at index n describes ref@{n}.
+func (self *repository) Reflog(ref string) ([]*operations.ReflogEntry, error) {
+	name, err := self.fullRefName(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	reflog, err := self.repo.ReadReflog(name)
+	if err != nil {
+		return nil, err
+	}
+	defer reflog.Free()
+
+	count := reflog.EntryCount()
+	entries := make([]*operations.ReflogEntry, 0, count)
+	for i := uint(0); i < count; i++ {
+		entry := reflog.EntryByIndex(i)
+		entries = append(entries, &operations.ReflogEntry{
+			Old: entry.Old.String(),
+			New: entry.New.String(),
+			Committer: operations.Identity{
+				Name:  entry.Committer.Name,
+				Email: entry.Committer.Email,
+				When:  entry.Committer.When,
+			},
+			Message: entry.Message,
+		})
+	}
+
+	return entries, nil
+}
+
+// fullRefName expands a ref to its full name, leaving HEAD as is.
+func (self *repository) fullRefName(ref string) (string, error) {
+	if ref == "" || ref == "HEAD" {
+		return "HEAD", nil
+	}
+
+	resolved, err := self.repo.References.Dwim(ref)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a ref", ref)
+	}
+
+	return resolved.Name(), nil
+}
+
+// ReflogExpire removes the entries older than the given time from
+// the reflog of a ref, or from every reflog when ref is empty.
+func (self *repository) ReflogExpire(ref string, before time.Time) error {
+	var names []string
+
+	if ref == "" {
+		iter, err := self.repo.NewReferenceIterator()
+		if err != nil {
+			return err
+		}
+		defer iter.Free()
+
+		names = append(names, "HEAD")
+		for {
+			ref, err := iter.Next()
+			if err != nil {
+				if git.IsErrorCode(err, git.ErrIterOver) {
+					break
+				}
+				return err
+			}
+			names = append(names, ref.Name())
+		}
+	} else {
+		name, err := self.fullRefName(ref)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		if err := self.expireReflog(name, before); err != nil {
+			return fmt.Errorf("could not expire reflog of %s: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (self *repository) expireReflog(name string, before time.Time) error {
+	reflog, err := self.repo.ReadReflog(name)
+	if err != nil {
+		return err
+	}
+	defer reflog.Free()
+
+	// entries are most recent first, so drop from the end to keep
+	// the remaining indexes stable.
+	dropped := false
+	for i := reflog.EntryCount(); i > 0; i-- {
+		if !reflog.EntryByIndex(i - 1).Committer.When.Before(before) {
+			continue
+		}
+
+		if err = reflog.Drop(i-1, false); err != nil {
+			return err
+		}
+		dropped = true
+	}
+
+	if !dropped {
+		return nil
+	}
+
+	return reflog.Write()
+}
+
+// UpdateRef points a ref at a new object, failing if the ref does
+// not currently point at expected, unless expected is empty. Updates
+// to HEAD move the branch that HEAD refers to. Refs other than HEAD
+// that are not full names must already exist.
+func (self *repository) UpdateRef(ref, target, expected, message string) error {
+	if ref != "HEAD" && !strings.HasPrefix(ref, "refs/") {
+		name, err := self.fullRefName(ref)
+		if err != nil {
+			return err
+		}
+		ref = name
+	}
+
+	obj, err := self.repo.RevparseSingle(target)
+	if err != nil {
+		return fmt.Errorf("could not resolve %s: %s", target, err)
+	}
+
+	if ref == "HEAD" {
+		head, err := self.repo.References.Lookup("HEAD")
+		if err != nil {
+			return err
+		}
+		if head.Type() == git.ReferenceSymbolic {
+			ref = head.SymbolicTarget()
+		}
+	}
+
+	if err = self.updateRef(ref, obj.Id(), expected != "", expected, message); err != nil {
+		return fmt.Errorf("could not update %s: %s", ref, err)
+	}
+
+	return nil
+}
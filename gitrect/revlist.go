@@ -0,0 +1,62 @@
+package gitrect
+
+import (
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) ResolveCommit(rev string) (string, error) {
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		return "", err
+	}
+
+	return commit.Id().String(), nil
+}
+
+func (self *repository) RevList(include, exclude []string) ([]*operations.CommitNode, error) {
+	walk, err := self.repo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+
+	walk.Sorting(git.SortTopological)
+
+	for _, rev := range include {
+		commit, err := self.lookupRevCommit(rev)
+		if err != nil {
+			return nil, err
+		}
+		if err = walk.Push(commit.Id()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, rev := range exclude {
+		commit, err := self.lookupRevCommit(rev)
+		if err != nil {
+			return nil, err
+		}
+		if err = walk.Hide(commit.Id()); err != nil {
+			return nil, err
+		}
+	}
+
+	var commits []*operations.CommitNode
+	err = walk.Iterate(func(commit *git.Commit) bool {
+		node := &operations.CommitNode{ID: commit.Id().String()}
+		for i := uint(0); i < commit.ParentCount(); i++ {
+			node.Parents = append(node.Parents, commit.ParentId(i).String())
+		}
+
+		commits = append(commits, node)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
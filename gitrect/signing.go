@@ -0,0 +1,107 @@
+package gitrect
+
+import (
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/signing"
+)
+
+func (self *repository) SetSigner(signer signing.Signer) {
+	self.signer = signer
+}
+
+func (self *repository) SetVerifier(verifier signing.Verifier) {
+	self.verifier = verifier
+}
+
+// getVerifier returns the configured verifier, or one that uses the
+// local GnuPG keyring and the repository's allowed SSH signers.
+func (self *repository) getVerifier() signing.Verifier {
+	if self.verifier != nil {
+		return self.verifier
+	}
+
+	allowedSigners, _ := self.Config().Get("gpg.ssh.allowedSignersFile")
+	return signing.NewVerifier(allowedSigners)
+}
+
+func formatSignature(sig *git.Signature) string {
+	return signing.FormatIdent(sig.Name, sig.Email, sig.When)
+}
+
+// writeSignedCommit writes a commit with a signature from the
+// repository's signer, which libgit2 cannot do on its own.
+func (self *repository) writeSignedCommit(author, committer *git.Signature, message string, tree *git.Tree, parents ...*git.Commit) (*git.Oid, error) {
+	commit := &signing.Commit{
+		Tree:      tree.Id().String(),
+		Author:    formatSignature(author),
+		Committer: formatSignature(committer),
+		Message:   message,
+	}
+	for _, parent := range parents {
+		commit.Parents = append(commit.Parents, parent.Id().String())
+	}
+
+	data, err := signing.SignCommit(commit, self.signer)
+	if err != nil {
+		return nil, err
+	}
+
+	odb, err := self.repo.Odb()
+	if err != nil {
+		return nil, err
+	}
+
+	return odb.Write(data, git.ObjectCommit)
+}
+
+// createSignedCommit writes a signed commit and moves the current
+// branch to it.
+func (self *repository) createSignedCommit(reason string, author, committer *git.Signature, message string, tree *git.Tree, parents ...*git.Commit) (*git.Oid, error) {
+	oid, err := self.writeSignedCommit(author, committer, message, tree, parents...)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := self.repo.References.Lookup("HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	// update the branch that HEAD refers to, which may not exist
+	// yet, rather than detaching HEAD.
+	name := head.Name()
+	if head.Type() == git.ReferenceSymbolic {
+		name = head.SymbolicTarget()
+	}
+
+	subject := strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+	if _, err = self.repo.References.Create(name, oid, true, reason+": "+subject); err != nil {
+		return nil, err
+	}
+
+	return oid, nil
+}
+
+// VerifyCommit checks the signature of the commit that a revision
+// refers to.
+func (self *repository) VerifyCommit(rev string) (*signing.Verification, error) {
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	odb, err := self.repo.Odb()
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := odb.Read(commit.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	return signing.VerifyCommit(obj.Data(), self.getVerifier())
+}
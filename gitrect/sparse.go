@@ -0,0 +1,277 @@
+package gitrect
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/config"
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+// libgit2 0.23 does not support sparse checkouts, so the direct
+// implementation keeps its patterns in the same file and settings
+// that git uses, and applies them to the working tree after each
+// checkout. It cannot set the skip-worktree bit on index entries,
+// so files outside of the patterns appear deleted to status.
+
+func (self *repository) sparseCheckoutPath() string {
+	return filepath.Join(self.repo.Path(), "info", "sparse-checkout")
+}
+
+// SparseCheckoutInit enables sparse checkout, limiting the working
+// tree to the files at the top level until patterns are set. In cone
+// mode, patterns are directories.
+func (self *repository) SparseCheckoutInit(cone bool) error {
+	if _, err := os.Stat(self.sparseCheckoutPath()); os.IsNotExist(err) {
+		if err = self.writeSparsePatterns(operations.FormatConePatterns(nil)); err != nil {
+			return err
+		}
+	}
+
+	return self.enableSparseCheckout(cone)
+}
+
+func (self *repository) SparseCheckoutSet(patterns ...string) error {
+	cone, err := self.isSparseCone()
+	if err != nil {
+		return err
+	}
+
+	if cone {
+		err = self.writeSparsePatterns(operations.FormatConePatterns(patterns))
+	} else {
+		err = self.writeSparsePatterns(patterns)
+	}
+	if err != nil {
+		return err
+	}
+
+	return self.enableSparseCheckout(cone)
+}
+
+func (self *repository) SparseCheckoutAdd(patterns ...string) error {
+	existing, err := self.SparseCheckoutList()
+	if err != nil {
+		return err
+	}
+
+	return self.SparseCheckoutSet(append(existing, patterns...)...)
+}
+
+func (self *repository) SparseCheckoutList() ([]string, error) {
+	enabled, err := self.isSparseCheckout()
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, fmt.Errorf("this worktree is not sparse")
+	}
+
+	lines, err := self.readSparsePatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	cone, err := self.isSparseCone()
+	if err != nil {
+		return nil, err
+	}
+	if cone {
+		return operations.ParseConePatterns(lines), nil
+	}
+
+	return lines, nil
+}
+
+// SparseCheckoutDisable restores the full working tree, leaving the
+// patterns in place for when sparse checkout is enabled again.
+func (self *repository) SparseCheckoutDisable() error {
+	if err := self.Config().SetBool(config.Local, "core.sparseCheckout", false); err != nil {
+		return err
+	}
+
+	if self.repo.IsBare() {
+		return nil
+	}
+
+	index, err := self.repo.Index()
+	if err != nil {
+		return err
+	}
+	defer index.Free()
+
+	err = self.repo.CheckoutIndex(index, self.checkoutOpts(git.CheckoutSafe|git.CheckoutRecreateMissing))
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	return nil
+}
+
+func (self *repository) enableSparseCheckout(cone bool) error {
+	conf := self.Config()
+	if err := conf.SetBool(config.Local, "core.sparseCheckout", true); err != nil {
+		return err
+	}
+	if err := conf.SetBool(config.Local, "core.sparseCheckoutCone", cone); err != nil {
+		return err
+	}
+
+	return self.applySparseCheckout()
+}
+
+func (self *repository) isSparseCheckout() (bool, error) {
+	enabled, err := self.Config().GetBool("core.sparseCheckout")
+	if err == config.ErrKeyNotFound {
+		return false, nil
+	}
+
+	return enabled, err
+}
+
+// isSparseCone returns the configured mode, which like git defaults
+// to cone mode.
+func (self *repository) isSparseCone() (bool, error) {
+	cone, err := self.Config().GetBool("core.sparseCheckoutCone")
+	if err == config.ErrKeyNotFound {
+		return true, nil
+	}
+
+	return cone, err
+}
+
+func (self *repository) readSparsePatterns() ([]string, error) {
+	data, err := ioutil.ReadFile(self.sparseCheckoutPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+func (self *repository) writeSparsePatterns(lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(self.sparseCheckoutPath()), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(self.sparseCheckoutPath(), []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// sparseMatcher returns the matcher for the current patterns, or nil
+// when sparse checkout is not enabled.
+func (self *repository) sparseMatcher() (*operations.SparseMatcher, error) {
+	enabled, err := self.isSparseCheckout()
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	cone, err := self.isSparseCone()
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := self.readSparsePatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	if cone {
+		return operations.NewSparseMatcher(true, operations.ParseConePatterns(lines)), nil
+	}
+
+	return operations.NewSparseMatcher(false, lines), nil
+}
+
+// sparseStrategy adds to a checkout strategy so that checkouts
+// restore files that a sparse checkout removed from the working
+// tree, rather than treating them as deleted.
+func (self *repository) sparseStrategy(strategy git.CheckoutStrategy) git.CheckoutStrategy {
+	if enabled, err := self.isSparseCheckout(); err == nil && enabled {
+		return strategy | git.CheckoutRecreateMissing
+	}
+
+	return strategy
+}
+
+// applySparseCheckout brings the working tree in line with the sparse
+// checkout patterns, restoring files that the patterns include and
+// removing unmodified files that they exclude. It does nothing when
+// sparse checkout is disabled.
+func (self *repository) applySparseCheckout() error {
+	if self.repo.IsBare() {
+		return nil
+	}
+
+	matcher, err := self.sparseMatcher()
+	if err != nil || matcher == nil {
+		return err
+	}
+
+	index, err := self.repo.Index()
+	if err != nil {
+		return err
+	}
+	defer index.Free()
+
+	err = self.repo.CheckoutIndex(index, self.checkoutOpts(git.CheckoutSafe|git.CheckoutRecreateMissing))
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	workdir := self.repo.Workdir()
+	for i := uint(0); i < index.EntryCount(); i++ {
+		entry, err := index.EntryByIndex(i)
+		if err != nil {
+			return err
+		}
+
+		if matcher.Includes(entry.Path) {
+			continue
+		}
+
+		status, err := self.repo.StatusFile(entry.Path)
+		if err != nil || status != git.StatusCurrent {
+			// like git, leave modified files in place
+			continue
+		}
+
+		fn := filepath.Join(workdir, filepath.FromSlash(entry.Path))
+		if err = os.Remove(fn); err != nil && !os.IsNotExist(err) {
+			self.state = states.IncompleteOperation
+			return err
+		}
+		removeEmptyParents(workdir, filepath.Dir(fn))
+	}
+
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents, up to but not
+// including root, for as long as they are empty.
+func removeEmptyParents(root, dir string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package gitrect
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+	"github.com/tychoish/grip"
+)
+
+func (self *repository) SetRecurseSubmodules(recurse bool) {
+	self.recurseSubmodules = recurse
+}
+
+// updateRecursiveSubmodules brings submodules in line with the
+// superproject after operations that modify the working tree, but
+// only when the repository is configured to recurse into submodules.
+func (self *repository) updateRecursiveSubmodules() error {
+	if !self.recurseSubmodules {
+		return nil
+	}
+
+	err := self.SubmoduleInit()
+	if err != nil {
+		return err
+	}
+
+	return self.SubmoduleUpdate(true)
+}
+
+// lookupSubmodules returns the submodules with the specified paths,
+// or all submodules if no paths are given. libgit2 only guarantees
+// that the submodules passed to the Foreach callback are valid for
+// the duration of the callback, so collect names and look them up
+// again.
+func (self *repository) lookupSubmodules(paths ...string) ([]*git.Submodule, error) {
+	var names []string
+	err := self.repo.Submodules.Foreach(func(sub *git.Submodule, name string) int {
+		names = append(names, name)
+		return 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filter := make(map[string]bool)
+	for _, p := range paths {
+		filter[filepath.Clean(p)] = true
+	}
+
+	var modules []*git.Submodule
+	for _, name := range names {
+		sub, err := self.repo.Submodules.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(filter) > 0 && !filter[filepath.Clean(sub.Path())] {
+			continue
+		}
+
+		modules = append(modules, sub)
+	}
+
+	if len(modules) < len(filter) {
+		return modules, fmt.Errorf("could not find all submodules in %v", paths)
+	}
+
+	return modules, nil
+}
+
+// openSubmodule returns a repository for a submodule's working tree,
+// that inherits the submodule recursion setting, credentials and
+// progress reporting of its parent.
+func (self *repository) openSubmodule(sub *git.Submodule) (*repository, error) {
+	subRepo, err := sub.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository{
+		path:              subRepo.Path(),
+		exists:            true,
+		repo:              subRepo,
+		recurseSubmodules: self.recurseSubmodules,
+		credentials:       self.credentials,
+		progress:          self.progress,
+	}, nil
+}
+
+func (self *repository) Submodules() ([]*operations.Submodule, error) {
+	if !self.exists {
+		return nil, fmt.Errorf("no repository exists at %s", self.path)
+	}
+
+	subs, err := self.lookupSubmodules()
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return nil, err
+	}
+
+	config, err := self.repo.Config()
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return nil, err
+	}
+	defer config.Free()
+
+	var modules []*operations.Submodule
+	for _, sub := range subs {
+		module := &operations.Submodule{
+			Name: sub.Name(),
+			Path: sub.Path(),
+			URL:  sub.Url(),
+		}
+
+		if id := sub.IndexId(); id != nil {
+			module.Recorded = id.String()
+		}
+
+		if isSubmoduleInitialized(config, sub) {
+			module.Status |= operations.SubmoduleInitialized
+		}
+
+		if id := sub.WdId(); id != nil {
+			module.Checkout = id.String()
+			if module.Checkout != module.Recorded {
+				module.Status |= operations.SubmoduleOutOfDate
+			}
+
+			if isSubmoduleDirty(sub) {
+				module.Status |= operations.SubmoduleDirty
+			}
+		}
+
+		modules = append(modules, module)
+	}
+
+	return modules, nil
+}
+
+// isSubmoduleInitialized reports if the submodule's URL has been
+// copied into the repository's configuration by SubmoduleInit.
+func isSubmoduleInitialized(config *git.Config, sub *git.Submodule) bool {
+	_, err := config.LookupString(fmt.Sprintf("submodule.%s.url", sub.Name()))
+	return err == nil
+}
+
+func isSubmoduleDirty(sub *git.Submodule) bool {
+	repo, err := sub.Open()
+	if err != nil {
+		return false
+	}
+	defer repo.Free()
+
+	status, err := repo.StatusList(&git.StatusOptions{
+		Show:  git.StatusShowIndexAndWorkdir,
+		Flags: git.StatusOptIncludeUntracked,
+	})
+	if err != nil {
+		grip.CatchError(err)
+		return false
+	}
+	defer status.Free()
+
+	count, err := status.EntryCount()
+	if err != nil {
+		grip.CatchError(err)
+		return false
+	}
+
+	return count > 0
+}
+
+func (self *repository) SubmoduleInit(paths ...string) error {
+	subs, err := self.lookupSubmodules(paths...)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	catcher := grip.NewCatcher()
+	for _, sub := range subs {
+		catcher.Add(sub.Init(false))
+	}
+
+	if catcher.HasErrors() {
+		self.state = states.PartialOperation
+	}
+
+	return catcher.Resolve()
+}
+
+// SubmoduleUpdate checks out the recorded commit in each initialized
+// submodule; uninitialized submodules are skipped, as they are by
+// "git submodule update". When recursive, the submodules nested
+// within each updated submodule are initialized and updated as well,
+// as there is no other way to initialize them.
+func (self *repository) SubmoduleUpdate(recursive bool, paths ...string) error {
+	subs, err := self.lookupSubmodules(paths...)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	config, err := self.repo.Config()
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+	defer config.Free()
+
+	opts := &git.SubmoduleUpdateOptions{
+		CheckoutOpts: self.checkoutOpts(git.CheckoutSafe),
+		FetchOptions: self.fetchOptions(),
+	}
+
+	catcher := grip.NewCatcher()
+	for _, sub := range subs {
+		if !isSubmoduleInitialized(config, sub) {
+			continue
+		}
+
+		// the first argument to Update initializes the
+		// submodule, which has already happened.
+		err = sub.Update(false, opts)
+		catcher.Add(err)
+		if err != nil || !recursive {
+			continue
+		}
+
+		subRepo, err := self.openSubmodule(sub)
+		if err != nil {
+			catcher.Add(err)
+			continue
+		}
+
+		err = subRepo.SubmoduleInit()
+		if err == nil {
+			err = subRepo.SubmoduleUpdate(recursive)
+		}
+		catcher.Add(err)
+		subRepo.repo.Free()
+	}
+
+	if catcher.HasErrors() {
+		self.state = states.PartialOperation
+	}
+
+	return catcher.Resolve()
+}
+
+func (self *repository) SubmoduleSync(recursive bool) error {
+	subs, err := self.lookupSubmodules()
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	catcher := grip.NewCatcher()
+	for _, sub := range subs {
+		err = sub.Sync()
+		catcher.Add(err)
+		if err != nil || !recursive {
+			continue
+		}
+
+		subRepo, err := self.openSubmodule(sub)
+		if err != nil {
+			// uninitialized submodules have nothing to sync.
+			continue
+		}
+
+		catcher.Add(subRepo.SubmoduleSync(recursive))
+		subRepo.repo.Free()
+	}
+
+	if catcher.HasErrors() {
+		self.state = states.PartialOperation
+	}
+
+	return catcher.Resolve()
+}
@@ -0,0 +1,175 @@
+package gitrect
+
+import (
+	"fmt"
+
+	"gopkg.in/libgit2/git2go.v23"
+
+	"github.com/tychoish/gitgone/signing"
+	"github.com/tychoish/gitgone/states"
+	"github.com/tychoish/grip"
+)
+
+// lookupRevCommit resolves a revision, which defaults to HEAD, to the
+// commit it refers to, peeling any tags.
+func (self *repository) lookupRevCommit(rev string) (*git.Commit, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	obj, err := self.repo.RevparseSingle(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	peeled, err := obj.Peel(git.ObjectCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	return self.repo.LookupCommit(peeled.Id())
+}
+
+func tagRef(name string) string {
+	return fmt.Sprintf("refs/tags/%s", name)
+}
+
+func (self *repository) CreateTag(name, rev, message string, force bool) error {
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	var oid *git.Oid
+	if message == "" {
+		oid, err = self.repo.Tags.CreateLightweight(name, commit, force)
+	} else {
+		oid, err = self.createAnnotatedTag(name, commit, message, force)
+	}
+
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	grip.Debugf("created tag '%s' of commit '%s' with hash '%s' in repo '%s'",
+		name, commit.Id(), oid, self.path)
+
+	return nil
+}
+
+// createAnnotatedTag writes a tag object, signed by the repository's
+// signer if there is one, and then points the tag's ref at it. libgit2
+// can neither sign tags nor replace annotated tags, and writing the
+// object before moving the ref means that a failure leaves any
+// existing tag in place.
+func (self *repository) createAnnotatedTag(name string, commit *git.Commit, message string, force bool) (*git.Oid, error) {
+	signature, err := self.repo.DefaultSignature()
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &signing.Tag{
+		Object:     commit.Id().String(),
+		ObjectType: "commit",
+		Name:       name,
+		Tagger:     signing.FormatIdent(signature.Name, signature.Email, signature.When),
+		Message:    message,
+	}
+
+	data := tag.Payload()
+	if self.signer != nil {
+		data, err = signing.SignTag(tag, self.signer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	odb, err := self.repo.Odb()
+	if err != nil {
+		return nil, err
+	}
+
+	oid, err := odb.Write(data, git.ObjectTag)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = self.repo.References.Create(tagRef(name), oid, force, "tag: tagging "+commit.Id().String())
+	if err != nil {
+		return nil, err
+	}
+
+	return oid, nil
+}
+
+func (self *repository) DeleteTag(name string) error {
+	tag, err := self.repo.References.Lookup(tagRef(name))
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	err = tag.Delete()
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	return err
+}
+
+func (self *repository) IsTagged(name, rev string, lightweight bool) bool {
+	ref, err := self.repo.References.Lookup(tagRef(name))
+	if err != nil {
+		return false
+	}
+
+	obj, err := self.repo.Lookup(ref.Target())
+	if err != nil {
+		return false
+	}
+
+	if lightweight == (obj.Type() == git.ObjectTag) {
+		return false
+	}
+
+	tagged, err := obj.Peel(git.ObjectCommit)
+	if err != nil {
+		return false
+	}
+
+	commit, err := self.lookupRevCommit(rev)
+	if err != nil {
+		grip.CatchError(err)
+		return false
+	}
+
+	return tagged.Id().Equal(commit.Id())
+}
+
+// VerifyTag checks the signature of an annotated tag. Lightweight and
+// unsigned tags are reported as unsigned.
+func (self *repository) VerifyTag(name string) (*signing.Verification, error) {
+	ref, err := self.repo.References.Lookup(tagRef(name))
+	if err != nil {
+		return nil, err
+	}
+
+	odb, err := self.repo.Odb()
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := odb.Read(ref.Target())
+	if err != nil {
+		return nil, err
+	}
+
+	if obj.Type() != git.ObjectTag {
+		return &signing.Verification{Status: signing.Unsigned}, nil
+	}
+
+	return signing.VerifyTag(obj.Data(), self.getVerifier())
+}
@@ -0,0 +1,31 @@
+package gitwrap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) Archive(rev string, format operations.ArchiveFormat, prefix string, writer io.Writer) error {
+	if err := format.Validate(); err != nil {
+		return err
+	}
+
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	cmd := self.gitCommand("archive", "--format", string(format), "--prefix", prefix, rev)
+	cmd.Stdout = writer
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("could not archive %s: %s", rev, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
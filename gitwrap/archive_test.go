@@ -0,0 +1,80 @@
+package gitwrap
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type ArchiveSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&ArchiveSuite{})
+
+func (s *ArchiveSuite) SetUpSuite(c *C) {
+	s.repo = newTestRepository(c)
+
+	files := map[string]string{
+		".gitattributes":  "*.md export-ignore\nRELEASE.md -export-ignore\n",
+		"README.md":       "readme",
+		"RELEASE.md":      "notes",
+		"main.go":         "package main",
+		"docs/index.html": "<html>",
+	}
+	for name, contents := range files {
+		fn := filepath.Join(s.repo.path, name)
+		c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fn, []byte(contents), 0644), IsNil)
+	}
+	c.Assert(s.repo.checkGitCommand("add", "."), IsNil)
+	c.Assert(s.repo.Commit("initial"), IsNil)
+}
+
+func (s *ArchiveSuite) TestTarArchive(c *C) {
+	buf := &bytes.Buffer{}
+	c.Assert(s.repo.Archive("", operations.ArchiveTar, "project/", buf), IsNil)
+
+	var names []string
+	reader := tar.NewReader(buf)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		if header.Typeflag != tar.TypeXGlobalHeader {
+			names = append(names, header.Name)
+		}
+	}
+	sort.Strings(names)
+
+	c.Assert(names, DeepEquals, []string{"project/", "project/.gitattributes",
+		"project/RELEASE.md", "project/docs/", "project/docs/index.html", "project/main.go"})
+}
+
+func (s *ArchiveSuite) TestZipArchive(c *C) {
+	buf := &bytes.Buffer{}
+	c.Assert(s.repo.Archive("HEAD", operations.ArchiveZip, "", buf), IsNil)
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	c.Assert(err, IsNil)
+
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{".gitattributes", "RELEASE.md", "docs/", "docs/index.html", "main.go"})
+
+	c.Assert(s.repo.Archive("HEAD", operations.ArchiveFormat("rar"), "", buf), NotNil)
+	c.Assert(s.repo.Archive("missing", operations.ArchiveTarGzip, "", buf), NotNil)
+}
@@ -0,0 +1,193 @@
+package gitwrap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) Blame(rev, path string, opts operations.BlameOptions) (*operations.BlameResult, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	args := []string{"blame", "--porcelain"}
+	if opts.StartLine > 0 || opts.EndLine > 0 {
+		start := opts.StartLine
+		if start < 1 {
+			start = 1
+		}
+
+		lines := strconv.Itoa(start) + ","
+		if opts.EndLine > 0 {
+			lines += strconv.Itoa(opts.EndLine)
+		}
+		args = append(args, "-L", lines)
+	}
+	if opts.IgnoreRevsFile != "" {
+		args = append(args, "--ignore-revs-file", opts.IgnoreRevsFile)
+	}
+	if opts.IgnoreWhitespace {
+		args = append(args, "-w")
+	}
+	args = append(args, rev, "--", path)
+
+	output, err := self.outputGitCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not blame %s at %s: %s", path, rev, commandError(err))
+	}
+
+	hunks, err := parseBlameOutput(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &operations.BlameResult{Path: path, Hunks: hunks}, nil
+}
+
+type blameCommit struct {
+	author string
+	email  string
+	when   int64
+	zone   string
+	path   string
+}
+
+// parseBlameOutput converts the output of "git blame --porcelain" into
+// hunks. The porcelain format only describes each commit the first
+// time it appears, so later hunks refer back to earlier entries.
+func parseBlameOutput(output []byte) ([]*operations.BlameHunk, error) {
+	var hunks []*operations.BlameHunk
+	commits := map[string]*blameCommit{}
+
+	var hunk *operations.BlameHunk
+	var commit *blameCommit
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "\t") {
+			// the content of the line ends the entry.
+			continue
+		}
+
+		key, value := line, ""
+		if idx := strings.Index(line, " "); idx >= 0 {
+			key, value = line[:idx], line[idx+1:]
+		}
+
+		if (len(key) == 40 || len(key) == 64) && isHex(key) {
+			fields := strings.Fields(value)
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("malformed blame entry: %s", line)
+			}
+
+			var ok bool
+			commit, ok = commits[key]
+			if !ok {
+				commit = &blameCommit{}
+				commits[key] = commit
+			}
+
+			if len(fields) != 3 {
+				// continues the current hunk.
+				continue
+			}
+
+			original, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("malformed blame entry: %s", line)
+			}
+			final, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("malformed blame entry: %s", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("malformed blame entry: %s", line)
+			}
+
+			hunk = &operations.BlameHunk{
+				Commit:        key,
+				OriginalStart: original,
+				Start:         final,
+				Lines:         count,
+			}
+			hunks = append(hunks, hunk)
+			continue
+		}
+
+		switch key {
+		case "author":
+			commit.author = value
+		case "author-mail":
+			commit.email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			commit.when, _ = strconv.ParseInt(value, 10, 64)
+		case "author-tz":
+			commit.zone = value
+		case "filename":
+			commit.path = value
+			if hunk != nil {
+				hunk.OriginalPath = value
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, hunk := range hunks {
+		commit := commits[hunk.Commit]
+		hunk.Author = commit.author
+		hunk.AuthorEmail = commit.email
+		hunk.AuthorTime = time.Unix(commit.when, 0).In(parseTimeZone(commit.zone))
+		if hunk.OriginalPath == "" {
+			hunk.OriginalPath = commit.path
+		}
+	}
+
+	return hunks, nil
+}
+
+func isHex(value string) bool {
+	for _, r := range value {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseTimeZone converts a git time zone offset, such as "-0500", to
+// a location.
+func parseTimeZone(zone string) *time.Location {
+	if len(zone) != 5 {
+		return time.UTC
+	}
+
+	hours, err := strconv.Atoi(zone[1:3])
+	if err != nil {
+		return time.UTC
+	}
+	minutes, err := strconv.Atoi(zone[3:])
+	if err != nil {
+		return time.UTC
+	}
+
+	offset := (hours*60 + minutes) * 60
+	if zone[0] == '-' {
+		offset = -offset
+	}
+
+	return time.FixedZone(zone, offset)
+}
@@ -0,0 +1,54 @@
+package gitwrap
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ParserSuite) TestBlameOutputParsing(c *C) {
+	first := "c6f3441f278c53181e50ab5572488c1a91b47bc4"
+	second := "d515d639411e48527f2978898e4af2168d337328"
+
+	hunks, err := parseBlameOutput([]byte(first + " 2 2 2\n" +
+		"author Alice\n" +
+		"author-mail <alice@example.com>\n" +
+		"author-time 1500000000\n" +
+		"author-tz -0130\n" +
+		"summary two\n" +
+		"previous " + second + " old\n" +
+		"filename new\n" +
+		"\tB\n" +
+		first + " 3 3\n" +
+		"\tC\n" +
+		second + " 3 4 1\n" +
+		"author Bob\n" +
+		"author-mail <bob@example.com>\n" +
+		"author-time 1400000000\n" +
+		"author-tz +0000\n" +
+		"boundary\n" +
+		"filename old\n" +
+		"\tauthor not a header\n" +
+		first + " 5 5 1\n" +
+		"\tE\n"))
+	c.Assert(err, IsNil)
+	c.Assert(hunks, HasLen, 3)
+
+	c.Assert(hunks[0].Commit, Equals, first)
+	c.Assert(hunks[0].Author, Equals, "Alice")
+	c.Assert(hunks[0].AuthorEmail, Equals, "alice@example.com")
+	c.Assert(hunks[0].AuthorTime.Equal(time.Unix(1500000000, 0)), Equals, true)
+	_, offset := hunks[0].AuthorTime.Zone()
+	c.Assert(offset, Equals, -90*60)
+	c.Assert(hunks[0].Start, Equals, 2)
+	c.Assert(hunks[0].Lines, Equals, 2)
+	c.Assert(hunks[0].OriginalPath, Equals, "new")
+
+	c.Assert(hunks[1].Author, Equals, "Bob")
+	c.Assert(hunks[1].OriginalPath, Equals, "old")
+	c.Assert(hunks[1].OriginalStart, Equals, 3)
+	c.Assert(hunks[1].Start, Equals, 4)
+
+	c.Assert(hunks[2].Author, Equals, "Alice")
+	c.Assert(hunks[2].OriginalPath, Equals, "new")
+}
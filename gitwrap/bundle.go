@@ -0,0 +1,85 @@
+package gitwrap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+// CreateBundle writes a bundle of the given refs and ranges, or of
+// every ref when none are given.
+func (self *repository) CreateBundle(bundle io.Writer, revs ...string) error {
+	if len(revs) == 0 {
+		revs = []string{"--all"}
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd := self.gitCommand(append([]string{"bundle", "create", "-"}, revs...)...)
+	cmd.Stdout = bundle
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("could not create bundle of %s: %s",
+			strings.Join(revs, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// VerifyBundle reads the header of a bundle, and returns an error
+// if the repository is missing any of the bundle's prerequisites.
+func (self *repository) VerifyBundle(path string) (*operations.Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	bundle, err := operations.ReadBundleHeader(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read bundle %s: %s", path, err)
+	}
+
+	output, err := self.runGitCommand("bundle", "verify", path)
+	if err != nil {
+		return bundle, fmt.Errorf("bundle %s is not valid for this repository: %s",
+			path, strings.Join(output, "\n"))
+	}
+
+	return bundle, nil
+}
+
+// FetchFromBundle fetches the refs in a bundle, by default into
+// remote-tracking refs under refs/remotes/bundle.
+func (self *repository) FetchFromBundle(path string, refspecs ...string) (*operations.FetchResult, error) {
+	if _, err := self.VerifyBundle(path); err != nil {
+		return nil, err
+	}
+
+	if len(refspecs) == 0 {
+		refspecs = []string{operations.DefaultBundleRefspec}
+	}
+
+	before, err := self.refSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := self.runGitCommand(append([]string{"fetch", "--quiet", path}, refspecs...)...)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return nil, fmt.Errorf("could not fetch from bundle %s: %s", path, strings.Join(output, "\n"))
+	}
+
+	after, err := self.refSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &operations.FetchResult{Updates: self.diffRefSnapshots(path, before, after)}, nil
+}
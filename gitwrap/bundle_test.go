@@ -0,0 +1,105 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type BundleSuite struct {
+	source *repository
+	dir    string
+}
+
+var _ = Suite(&BundleSuite{})
+
+func (s *BundleSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+	s.source = newTestRepository(c)
+	c.Assert(s.source.checkGitCommand("commit", "--allow-empty", "-m", "first"), IsNil)
+	c.Assert(s.source.checkGitCommand("commit", "--allow-empty", "-m", "second"), IsNil)
+}
+
+func (s *BundleSuite) writeBundle(c *C, name string, revs ...string) string {
+	path := filepath.Join(s.dir, name)
+	file, err := os.Create(path)
+	c.Assert(err, IsNil)
+	defer file.Close()
+
+	c.Assert(s.source.CreateBundle(file, revs...), IsNil)
+	return path
+}
+
+func (s *BundleSuite) TestFullBundle(c *C) {
+	head, err := s.source.getRef("master")
+	c.Assert(err, IsNil)
+
+	path := s.writeBundle(c, "full.bundle", "master")
+
+	target := newTestRepository(c)
+	bundle, err := target.VerifyBundle(path)
+	c.Assert(err, IsNil)
+	c.Assert(bundle.Version, Equals, 2)
+	c.Assert(bundle.Prerequisites, HasLen, 0)
+	c.Assert(bundle.Refs, HasLen, 1)
+	c.Assert(bundle.Refs[0].Name, Equals, "refs/heads/master")
+	c.Assert(bundle.Refs[0].Commit, Equals, head)
+
+	result, err := target.FetchFromBundle(path)
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 1)
+	c.Assert(result.Updates[0].Ref, Equals, "refs/remotes/bundle/master")
+	c.Assert(result.Updates[0].New, Equals, head)
+	c.Assert(result.Updates[0].IsCreated(), Equals, true)
+}
+
+func (s *BundleSuite) TestIncrementalBundle(c *C) {
+	target := newTestRepository(c)
+	_, err := target.FetchFromBundle(s.writeBundle(c, "full.bundle"), "refs/heads/master:refs/heads/imported")
+	c.Assert(err, IsNil)
+
+	c.Assert(s.source.checkGitCommand("commit", "--allow-empty", "-m", "third"), IsNil)
+	path := s.writeBundle(c, "incremental.bundle", "master~1..master")
+
+	bundle, err := newTestRepository(c).VerifyBundle(path)
+	c.Assert(err, NotNil)
+	c.Assert(bundle.Prerequisites, HasLen, 1)
+	c.Assert(bundle.Prerequisites[0].Name, Equals, "second")
+
+	result, err := target.FetchFromBundle(path, "refs/heads/master:refs/heads/imported")
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 1)
+	c.Assert(result.Updates[0].Forced, Equals, false)
+
+	subject, err := target.runGitCommand("log", "-1", "--format=%s", "imported")
+	c.Assert(err, IsNil)
+	c.Assert(subject[0], Equals, "third")
+
+	empty := filepath.Join(s.dir, "empty.bundle")
+	c.Assert(ioutil.WriteFile(empty, []byte("not a bundle\n"), 0644), IsNil)
+	_, err = target.VerifyBundle(empty)
+	c.Assert(err, ErrorMatches, ".*not a git bundle.*")
+}
+
+func (s *BundleSuite) TestAllRefs(c *C) {
+	head, err := s.source.getRef("master")
+	c.Assert(err, IsNil)
+
+	// a tag of a tree does not peel to a commit, but does not stop
+	// a bundle of every ref.
+	c.Assert(s.source.checkGitCommand("tag", "-a", "-m", "tree", "tree", "master^{tree}"), IsNil)
+	c.Assert(s.source.checkGitCommand("tag", "-a", "-m", "release", "v1.0", "master"), IsNil)
+
+	bundle, err := newTestRepository(c).VerifyBundle(s.writeBundle(c, "all.bundle"))
+	c.Assert(err, IsNil)
+
+	refs := map[string]string{}
+	for _, ref := range bundle.Refs {
+		refs[ref.Name] = ref.Commit
+	}
+	c.Assert(refs["refs/heads/master"], Equals, head)
+	c.Assert(refs["HEAD"], Equals, head)
+	c.Assert(refs["refs/tags/v1.0"], Not(Equals), "")
+}
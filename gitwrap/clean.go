@@ -0,0 +1,68 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) Clean(opts operations.CleanOptions) ([]string, error) {
+	if self.bare || !self.exists {
+		return nil, fmt.Errorf("cannot modify the working tree of this repository")
+	}
+
+	args := []string{"-c", "core.quotePath=false", "clean"}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	} else {
+		args = append(args, "--force")
+	}
+	if opts.Directories {
+		args = append(args, "-d")
+	}
+	if opts.IgnoredOnly {
+		args = append(args, "-X")
+	} else if opts.IncludeIgnored {
+		args = append(args, "-x")
+	}
+	args = append(args, "--")
+	args = append(args, opts.Pathspecs...)
+
+	output, err := self.outputGitCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not clean %s: %s", self.path, commandError(err))
+	}
+
+	return parseCleanOutput(string(output)), nil
+}
+
+// parseCleanOutput returns the paths in the output of "git clean",
+// which reports "Would remove <path>" for dry runs and "Removing
+// <path>" otherwise, quoting paths with special characters.
+func parseCleanOutput(output string) []string {
+	var paths []string
+
+	for _, line := range strings.Split(output, "\n") {
+		var path string
+		switch {
+		case strings.HasPrefix(line, "Would remove "):
+			path = strings.TrimPrefix(line, "Would remove ")
+		case strings.HasPrefix(line, "Removing "):
+			path = strings.TrimPrefix(line, "Removing ")
+		default:
+			continue
+		}
+
+		if strings.HasPrefix(path, `"`) {
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths
+}
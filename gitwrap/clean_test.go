@@ -0,0 +1,55 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+func (s *ParserSuite) TestCleanOutputParsing(c *C) {
+	c.Assert(parseCleanOutput("Would remove a.txt\nWould remove \"new\\nline\"\nWould remove dir/\n"),
+		DeepEquals, []string{"a.txt", "new\nline", "dir/"})
+	c.Assert(parseCleanOutput("Removing sp ace\nSkipping repository nested/\n"),
+		DeepEquals, []string{"sp ace"})
+	c.Assert(parseCleanOutput(""), HasLen, 0)
+}
+
+type CleanSuite struct{}
+
+var _ = Suite(&CleanSuite{})
+
+func (s *CleanSuite) TestClean(c *C) {
+	repo := NewRepository(c.MkDir())
+	c.Assert(repo.Init(false, "master", ""), IsNil)
+
+	for _, name := range []string{".gitignore", "tracked/file", "tracked/new", "build.log", "untracked/file"} {
+		fn := filepath.Join(repo.path, name)
+		c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fn, []byte("*.log\n"), 0644), IsNil)
+	}
+	c.Assert(repo.Stage(".gitignore", "tracked/file"), IsNil)
+
+	paths, err := repo.Clean(operations.CleanOptions{DryRun: true})
+	c.Assert(err, IsNil)
+	c.Assert(paths, DeepEquals, []string{"tracked/new"})
+
+	paths, err = repo.Clean(operations.CleanOptions{DryRun: true, Directories: true, IncludeIgnored: true})
+	c.Assert(err, IsNil)
+	c.Assert(paths, DeepEquals, []string{"build.log", "tracked/new", "untracked/"})
+
+	paths, err = repo.Clean(operations.CleanOptions{IgnoredOnly: true})
+	c.Assert(err, IsNil)
+	c.Assert(paths, DeepEquals, []string{"build.log"})
+
+	paths, err = repo.Clean(operations.CleanOptions{Directories: true, Pathspecs: []string{"untracked"}})
+	c.Assert(err, IsNil)
+	c.Assert(paths, DeepEquals, []string{"untracked/"})
+
+	_, err = os.Stat(filepath.Join(repo.path, "untracked"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+	_, err = os.Stat(filepath.Join(repo.path, "tracked", "new"))
+	c.Assert(err, IsNil)
+}
@@ -0,0 +1,138 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type CloneSuite struct {
+	source *repository
+	url    string
+}
+
+var _ = Suite(&CloneSuite{})
+
+// SetUpSuite creates a source repository with three commits on master
+// and one on a feature branch. Shallow and partial clones are ignored
+// for local paths, so tests of those use a file:// URL.
+func (s *CloneSuite) SetUpSuite(c *C) {
+	s.source = newTestRepository(c)
+	s.url = "file://" + s.source.path
+	c.Assert(s.source.checkGitCommand("config", "uploadpack.allowFilter", "true"), IsNil)
+
+	for _, content := range []string{"one", "two", "three"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(s.source.path, "file"), []byte(content), 0644), IsNil)
+		c.Assert(s.source.Stage("file"), IsNil)
+		c.Assert(s.source.Commit(content), IsNil)
+	}
+
+	c.Assert(s.source.checkGitCommand("checkout", "--quiet", "-b", "feature"), IsNil)
+	c.Assert(s.source.checkGitCommand("commit", "--allow-empty", "-m", "feature"), IsNil)
+	c.Assert(s.source.checkGitCommand("checkout", "--quiet", "master"), IsNil)
+}
+
+func (s *CloneSuite) clone(c *C, remote string, opts operations.CloneOptions) *repository {
+	repo := NewRepository(filepath.Join(c.MkDir(), "clone"))
+	c.Assert(repo.CloneWithOptions(remote, opts), IsNil)
+	return repo
+}
+
+func (s *CloneSuite) git(c *C, repo *repository, args ...string) string {
+	output, err := repo.runGitCommand(args...)
+	c.Assert(err, IsNil, Commentf("%s", strings.Join(output, "\n")))
+	return strings.Join(output, "\n")
+}
+
+func (s *CloneSuite) TestClone(c *C) {
+	repo := s.clone(c, s.source.path, operations.CloneOptions{})
+	c.Assert(repo.IsExists(), Equals, true)
+	c.Assert(repo.IsBare(), Equals, false)
+	c.Assert(repo.Branch(), Equals, "master")
+	c.Assert(s.git(c, repo, "rev-list", "--count", "HEAD"), Equals, "3")
+	c.Assert(s.git(c, repo, "branch", "-r", "--format=%(refname:short)"), Equals, "origin/HEAD\norigin/feature\norigin/master")
+
+	data, err := ioutil.ReadFile(filepath.Join(repo.path, "file"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "three")
+
+	c.Logf("cloning into an existing repository fails")
+	c.Assert(repo.CloneWithOptions(s.source.path, operations.CloneOptions{}), NotNil)
+}
+
+func (s *CloneSuite) TestCloneBranch(c *C) {
+	repo := s.clone(c, s.source.path, operations.CloneOptions{Branch: "feature"})
+	c.Assert(repo.Branch(), Equals, "feature")
+	c.Assert(s.git(c, repo, "log", "-1", "--format=%s"), Equals, "feature")
+
+	repo = s.clone(c, s.source.path, operations.CloneOptions{Branch: "feature", SingleBranch: true})
+	c.Assert(repo.Branch(), Equals, "feature")
+	c.Assert(s.git(c, repo, "branch", "-r", "--format=%(refname:short)"), Equals, "origin/feature")
+}
+
+func (s *CloneSuite) TestShallowClone(c *C) {
+	repo := s.clone(c, s.url, operations.CloneOptions{Depth: 1})
+	c.Assert(s.git(c, repo, "rev-parse", "--is-shallow-repository"), Equals, "true")
+	c.Assert(s.git(c, repo, "rev-list", "--count", "HEAD"), Equals, "1")
+
+	c.Logf("as with git, shallow clones only fetch one branch")
+	c.Assert(s.git(c, repo, "config", "remote.origin.fetch"), Equals, "+refs/heads/master:refs/remotes/origin/master")
+
+	c.Assert(repo.Deepen(1), IsNil)
+	c.Assert(s.git(c, repo, "rev-list", "--count", "HEAD"), Equals, "2")
+
+	c.Assert(repo.Unshallow(), IsNil)
+	c.Assert(s.git(c, repo, "rev-parse", "--is-shallow-repository"), Equals, "false")
+	c.Assert(s.git(c, repo, "rev-list", "--count", "HEAD"), Equals, "3")
+
+	c.Logf("unshallowing a complete repository is a no-op")
+	c.Assert(repo.Unshallow(), IsNil)
+}
+
+func (s *CloneSuite) TestShallowUpstream(c *C) {
+	repo := s.clone(c, s.url, operations.CloneOptions{Depth: 1})
+	c.Assert(repo.checkGitCommand("remote", "rename", "origin", "upstream"), IsNil)
+
+	c.Logf("deepening fetches from the branch's remote, rather than origin")
+	c.Assert(repo.Deepen(1), IsNil)
+	c.Assert(s.git(c, repo, "rev-list", "--count", "HEAD"), Equals, "2")
+
+	c.Assert(repo.Unshallow(), IsNil)
+	c.Assert(s.git(c, repo, "rev-parse", "--is-shallow-repository"), Equals, "false")
+}
+
+func (s *CloneSuite) TestPartialClone(c *C) {
+	repo := s.clone(c, s.url, operations.CloneOptions{Filter: "blob:none", NoCheckout: true})
+	c.Assert(s.git(c, repo, "config", "remote.origin.promisor"), Equals, "true")
+	c.Assert(s.git(c, repo, "config", "remote.origin.partialclonefilter"), Equals, "blob:none")
+
+	// without a checkout, no blobs have been fetched.
+	missing := s.git(c, repo, "rev-list", "--objects", "--all", "--missing=print")
+	c.Assert(strings.Count(missing, "\n?"), Equals, 3)
+}
+
+func (s *CloneSuite) TestNoCheckout(c *C) {
+	repo := s.clone(c, s.source.path, operations.CloneOptions{NoCheckout: true})
+	_, err := os.Stat(filepath.Join(repo.path, "file"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+	c.Assert(s.git(c, repo, "rev-list", "--count", "HEAD"), Equals, "3")
+}
+
+func (s *CloneSuite) TestMirror(c *C) {
+	repo := s.clone(c, s.source.path, operations.CloneOptions{Mirror: true})
+	c.Assert(repo.IsBare(), Equals, true)
+	c.Assert(repo.BranchExists("feature"), Equals, true)
+	c.Assert(s.git(c, repo, "config", "remote.origin.mirror"), Equals, "true")
+}
+
+func (s *CloneSuite) TestReferences(c *C) {
+	repo := s.clone(c, s.url, operations.CloneOptions{References: []string{s.source.path}})
+
+	alternates, err := ioutil.ReadFile(filepath.Join(repo.path, ".git", "objects", "info", "alternates"))
+	c.Assert(err, IsNil)
+	c.Assert(strings.TrimSpace(string(alternates)), Equals, filepath.Join(s.source.path, ".git", "objects"))
+}
@@ -0,0 +1,357 @@
+package gitwrap
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/signing"
+)
+
+// treeEntry is an entry of a tree, as listed by "git ls-tree".
+type treeEntry struct {
+	mode operations.FileMode
+	kind string
+	id   string
+	name string
+}
+
+// readTree lists the entries of a tree; an empty id is the empty
+// tree.
+func (self *repository) readTree(id string) ([]*treeEntry, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	output, err := self.outputGitCommand("ls-tree", "-z", id)
+	if err != nil {
+		return nil, fmt.Errorf("could not read tree %s: %s", id, commandError(err))
+	}
+
+	return parseTreeEntries(output)
+}
+
+func parseTreeEntries(output []byte) ([]*treeEntry, error) {
+	var entries []*treeEntry
+
+	for _, record := range bytes.Split(output, []byte{0}) {
+		if len(record) == 0 {
+			continue
+		}
+
+		tab := bytes.IndexByte(record, '\t')
+		if tab < 0 {
+			return nil, fmt.Errorf("malformed tree entry: %q", record)
+		}
+
+		fields := strings.Fields(string(record[:tab]))
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed tree entry: %q", record)
+		}
+
+		mode, err := strconv.ParseInt(fields[0], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("malformed tree entry: %q", record)
+		}
+
+		entries = append(entries, &treeEntry{
+			mode: operations.FileMode(mode),
+			kind: fields[1],
+			id:   fields[2],
+			name: string(record[tab+1:]),
+		})
+	}
+
+	return entries, nil
+}
+
+func (self *repository) writeTree(entries []*treeEntry) (string, error) {
+	buf := &bytes.Buffer{}
+	for _, entry := range entries {
+		fmt.Fprintf(buf, "%06o %s %s\t%s\x00", int(entry.mode), entry.kind, entry.id, entry.name)
+	}
+
+	output, err := self.inputGitCommand(buf.Bytes(), "mktree", "-z")
+	if err != nil {
+		return "", fmt.Errorf("could not write tree: %s", strings.Join(output, "\n"))
+	}
+
+	return output[0], nil
+}
+
+// lookupTreeEntry returns the entry at a path in a tree, or nil if
+// there is none.
+func (self *repository) lookupTreeEntry(tree string, parts []string) (*treeEntry, error) {
+	var entry *treeEntry
+	for _, part := range parts {
+		if entry != nil {
+			if entry.kind != "tree" {
+				return nil, nil
+			}
+			tree = entry.id
+		}
+
+		entries, err := self.readTree(tree)
+		if err != nil {
+			return nil, err
+		}
+
+		entry = nil
+		for _, candidate := range entries {
+			if candidate.name == part {
+				entry = candidate
+				break
+			}
+		}
+		if entry == nil {
+			return nil, nil
+		}
+	}
+
+	return entry, nil
+}
+
+// setTreeEntry returns a new tree, in which the entry at the path is
+// replaced, or removed if the entry is nil. Only the trees along the
+// path are rewritten, and directories left empty are removed, since
+// git does not record empty trees.
+func (self *repository) setTreeEntry(tree string, parts []string, entry *treeEntry) (string, error) {
+	entries, err := self.readTree(tree)
+	if err != nil {
+		return "", err
+	}
+
+	var existing *treeEntry
+	updated := make([]*treeEntry, 0, len(entries)+1)
+	for _, candidate := range entries {
+		if candidate.name == parts[0] {
+			existing = candidate
+			continue
+		}
+		updated = append(updated, candidate)
+	}
+
+	replacement := entry
+	if len(parts) > 1 {
+		subtree := ""
+		if existing != nil && existing.kind == "tree" {
+			subtree = existing.id
+		}
+
+		id, err := self.setTreeEntry(subtree, parts[1:], entry)
+		if err != nil {
+			return "", err
+		}
+
+		replacement = nil
+		if id != emptyTree {
+			replacement = &treeEntry{mode: operations.ModeTree, kind: "tree", id: id}
+		}
+	}
+
+	if replacement != nil {
+		updated = append(updated, &treeEntry{
+			mode: replacement.mode,
+			kind: replacement.kind,
+			id:   replacement.id,
+			name: parts[0],
+		})
+	}
+
+	return self.writeTree(updated)
+}
+
+// emptyTree is the id of the tree with no entries.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// applyChange applies a change to a tree and returns the new tree.
+func (self *repository) applyChange(tree string, change *operations.FileChange) (string, error) {
+	parts, err := operations.SplitTreePath(change.Path)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := self.lookupTreeEntry(tree, parts)
+	if err != nil {
+		return "", err
+	}
+
+	switch change.Kind {
+	case operations.FileWrite:
+		output, err := self.inputGitCommand(change.Contents, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return "", fmt.Errorf("could not write %s: %s", change.Path, strings.Join(output, "\n"))
+		}
+
+		mode := change.Mode
+		if mode == 0 {
+			mode = operations.ModeRegular
+			if existing != nil && existing.mode.IsFile() {
+				mode = existing.mode
+			}
+		}
+
+		return self.setTreeEntry(tree, parts, &treeEntry{mode: mode, kind: "blob", id: output[0]})
+	case operations.FileDelete:
+		if existing == nil {
+			return "", fmt.Errorf("cannot delete %s, it does not exist", change.Path)
+		}
+
+		return self.setTreeEntry(tree, parts, nil)
+	case operations.FileRename:
+		from, err := operations.SplitTreePath(change.From)
+		if err != nil {
+			return "", err
+		}
+
+		source, err := self.lookupTreeEntry(tree, from)
+		if err != nil {
+			return "", err
+		}
+		if source == nil {
+			return "", fmt.Errorf("cannot rename %s, it does not exist", change.From)
+		}
+
+		tree, err = self.setTreeEntry(tree, from, nil)
+		if err != nil {
+			return "", err
+		}
+
+		return self.setTreeEntry(tree, parts, source)
+	case operations.FileSetMode:
+		if existing == nil || !existing.mode.IsFile() || !change.Mode.IsFile() {
+			return "", fmt.Errorf("cannot change the mode of %s", change.Path)
+		}
+
+		return self.setTreeEntry(tree, parts, &treeEntry{mode: change.Mode, kind: "blob", id: existing.id})
+	default:
+		return "", fmt.Errorf("unknown change to %s", change.Path)
+	}
+}
+
+func identityEnv(prefix string, ident *operations.Identity) []string {
+	if ident == nil {
+		return nil
+	}
+
+	when := ident.When
+	if when.IsZero() {
+		when = time.Now()
+	}
+
+	return []string{
+		prefix + "_NAME=" + ident.Name,
+		prefix + "_EMAIL=" + ident.Email,
+		prefix + "_DATE=" + fmt.Sprintf("%d %s", when.Unix(), when.Format("-0700")),
+	}
+}
+
+func (self *repository) WriteCommit(req operations.CommitRequest) (string, error) {
+	tree := emptyTree
+	var parents []string
+	if req.Parent != "" {
+		parent, err := self.ResolveCommit(req.Parent)
+		if err != nil {
+			return "", err
+		}
+		parents = append(parents, parent)
+
+		tree, err = self.getRef(parent + "^{tree}")
+		if err != nil {
+			return "", fmt.Errorf("could not read the tree of %s", req.Parent)
+		}
+	}
+
+	var err error
+	for _, change := range req.Changes {
+		tree, err = self.applyChange(tree, change)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	env := append(os.Environ(), identityEnv("GIT_AUTHOR", req.Author)...)
+	env = append(env, identityEnv("GIT_COMMITTER", req.Committer)...)
+
+	var commit string
+	if self.signer != nil {
+		commit, err = self.writeSignedCommitTree(env, tree, parents, req.Message)
+	} else {
+		args := []string{"commit-tree", tree}
+		for _, parent := range parents {
+			args = append(args, "-p", parent)
+		}
+
+		cmd := self.gitCommand(args...)
+		cmd.Env = env
+		cmd.Stdin = strings.NewReader(req.Message)
+
+		var output []byte
+		output, err = cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("could not write commit: %s", commandError(err))
+		}
+		commit = strings.TrimSpace(string(output))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if req.Ref == "" {
+		return commit, nil
+	}
+
+	subject := strings.SplitN(strings.TrimSpace(req.Message), "\n", 2)[0]
+	args := []string{"update-ref", "-m", "commit: " + subject, req.Ref, commit}
+	if req.CompareAndSwap {
+		args = append(args, req.Expected)
+	}
+
+	output, err := self.runGitCommand(args...)
+	if err != nil {
+		return commit, fmt.Errorf("could not update %s: %s", req.Ref, strings.Join(output, "\n"))
+	}
+
+	return commit, nil
+}
+
+// writeSignedCommitTree writes a commit signed by the repository's
+// signer, using the identities from the environment.
+func (self *repository) writeSignedCommitTree(env []string, tree string, parents []string, message string) (string, error) {
+	ident := func(variable string) (string, error) {
+		cmd := self.gitCommand("var", variable)
+		cmd.Env = env
+
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("could not determine identity: %s", commandError(err))
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	commit := &signing.Commit{Tree: tree, Parents: parents, Message: message}
+
+	var err error
+	if commit.Author, err = ident("GIT_AUTHOR_IDENT"); err != nil {
+		return "", err
+	}
+	if commit.Committer, err = ident("GIT_COMMITTER_IDENT"); err != nil {
+		return "", err
+	}
+
+	data, err := signing.SignCommit(commit, self.signer)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := self.inputGitCommand(data, "hash-object", "-t", "commit", "-w", "--stdin")
+	if err != nil {
+		return "", fmt.Errorf("could not write commit: %s", strings.Join(output, "\n"))
+	}
+
+	return output[0], nil
+}
@@ -0,0 +1,188 @@
+package gitwrap
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/tychoish/gitgone/config"
+)
+
+// repoConfig implements config.Config by calling "git config", using
+// NUL delimited output so that values with newlines survive intact.
+type repoConfig struct {
+	repo *repository
+}
+
+func (self *repository) Config() config.Config {
+	return &repoConfig{repo: self}
+}
+
+func scopeFlag(scope config.Scope) (string, error) {
+	switch scope {
+	case config.System, config.Global, config.Local, config.Worktree:
+		return "--" + scope.String(), nil
+	default:
+		return "", fmt.Errorf("cannot write configuration to scope '%s'", scope)
+	}
+}
+
+// getValues runs a "git config" query and returns its NUL delimited
+// values, translating git's exit code for missing keys into
+// config.ErrKeyNotFound.
+func (c *repoConfig) getValues(args ...string) ([]string, error) {
+	args = append([]string{"config", "--null", "--includes"}, args...)
+
+	output, err := c.repo.outputGitCommand(args...)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, config.ErrKeyNotFound
+		}
+		return nil, err
+	}
+
+	values := strings.Split(string(output), "\x00")
+	return values[:len(values)-1], nil
+}
+
+func (c *repoConfig) Get(key string) (string, error) {
+	values, err := c.getValues("--get", key)
+	if err != nil {
+		return "", err
+	}
+
+	return values[0], nil
+}
+
+func (c *repoConfig) GetAll(key string) ([]string, error) {
+	return c.getValues("--get-all", key)
+}
+
+func (c *repoConfig) GetBool(key string) (bool, error) {
+	values, err := c.getValues("--type=bool", "--get", key)
+	if err != nil {
+		return false, err
+	}
+
+	return strconv.ParseBool(values[0])
+}
+
+func (c *repoConfig) GetInt(key string) (int64, error) {
+	values, err := c.getValues("--type=int", "--get", key)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(values[0], 10, 64)
+}
+
+func (c *repoConfig) List(scope config.Scope) ([]*config.Entry, error) {
+	args := []string{"config", "--null", "--show-scope", "--show-origin"}
+	if scope == config.Any {
+		args = append(args, "--includes")
+	} else {
+		flag, err := scopeFlag(scope)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, flag)
+	}
+	args = append(args, "--list")
+
+	output, err := c.repo.outputGitCommand(args...)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			// the scope's file does not exist.
+			return []*config.Entry{}, nil
+		}
+		return nil, err
+	}
+
+	return parseConfigList(output)
+}
+
+// parseConfigList parses the output of "git config --null
+// --show-scope --show-origin --list", where every entry is made up
+// of three NUL terminated fields: the scope, the origin and the key
+// and value, which are separated by a newline. Keys without a value
+// (implicit booleans) have no newline.
+func parseConfigList(output []byte) ([]*config.Entry, error) {
+	fields := bytes.Split(output, []byte{0})
+	if len(fields) > 0 && len(fields[len(fields)-1]) == 0 {
+		fields = fields[:len(fields)-1]
+	}
+
+	if len(fields)%3 != 0 {
+		return nil, fmt.Errorf("malformed configuration listing with %d fields", len(fields))
+	}
+
+	entries := make([]*config.Entry, 0, len(fields)/3)
+	for i := 0; i < len(fields); i += 3 {
+		entry := &config.Entry{
+			Scope:  parseScope(string(fields[i])),
+			Origin: string(fields[i+1]),
+		}
+
+		kv := strings.SplitN(string(fields[i+2]), "\n", 2)
+		entry.Key = kv[0]
+		if len(kv) == 2 {
+			entry.Value = kv[1]
+		} else {
+			entry.Value = "true"
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func parseScope(name string) config.Scope {
+	for _, scope := range []config.Scope{config.System, config.Global, config.Local, config.Worktree} {
+		if scope.String() == name {
+			return scope
+		}
+	}
+
+	return config.Any
+}
+
+func (c *repoConfig) write(scope config.Scope, args ...string) error {
+	flag, err := scopeFlag(scope)
+	if err != nil {
+		return err
+	}
+
+	output, err := c.repo.runGitCommand(append([]string{"config", flag}, args...)...)
+	if err != nil {
+		return fmt.Errorf("problem writing %s configuration: %s", scope, strings.Join(output, "\n"))
+	}
+
+	return nil
+}
+
+func (c *repoConfig) Set(scope config.Scope, key, value string) error {
+	return c.write(scope, key, value)
+}
+
+func (c *repoConfig) SetBool(scope config.Scope, key string, value bool) error {
+	return c.write(scope, "--type=bool", key, strconv.FormatBool(value))
+}
+
+func (c *repoConfig) SetInt(scope config.Scope, key string, value int64) error {
+	return c.write(scope, "--type=int", key, strconv.FormatInt(value, 10))
+}
+
+func (c *repoConfig) Add(scope config.Scope, key, value string) error {
+	return c.write(scope, "--add", key, value)
+}
+
+func (c *repoConfig) Unset(scope config.Scope, key string) error {
+	return c.write(scope, "--unset", key)
+}
+
+func (c *repoConfig) UnsetAll(scope config.Scope, key string) error {
+	return c.write(scope, "--unset-all", key)
+}
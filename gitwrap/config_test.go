@@ -0,0 +1,46 @@
+package gitwrap
+
+import (
+	"testing"
+
+	"github.com/tychoish/gitgone/config"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ParserSuite struct{}
+
+var _ = Suite(&ParserSuite{})
+
+func (s *ParserSuite) TestConfigListParsing(c *C) {
+	output := []byte("global\x00file:/home/user/.gitconfig\x00user.name\nA User\x00" +
+		"local\x00file:.git/config\x00core.bare\nfalse\x00" +
+		"local\x00file:.git/config\x00remote.origin.fetch\n+refs/heads/*:refs/remotes/origin/*\x00" +
+		"local\x00file:.git/config\x00alias.lg\nlog\n--graph\x00" +
+		"local\x00file:.git/config\x00core.implicit\x00")
+
+	entries, err := parseConfigList(output)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 5)
+
+	c.Assert(entries[0].Scope, Equals, config.Global)
+	c.Assert(entries[0].Origin, Equals, "file:/home/user/.gitconfig")
+	c.Assert(entries[0].Key, Equals, "user.name")
+	c.Assert(entries[0].Value, Equals, "A User")
+
+	c.Assert(entries[1].Scope, Equals, config.Local)
+	c.Assert(entries[2].Value, Equals, "+refs/heads/*:refs/remotes/origin/*")
+	c.Assert(entries[3].Value, Equals, "log\n--graph")
+	c.Assert(entries[4].Key, Equals, "core.implicit")
+	c.Assert(entries[4].Value, Equals, "true")
+}
+
+func (s *ParserSuite) TestConfigListParsingRejectsTruncatedOutput(c *C) {
+	_, err := parseConfigList([]byte("local\x00file:.git/config\x00"))
+	c.Assert(err, NotNil)
+
+	entries, err := parseConfigList([]byte{})
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 0)
+}
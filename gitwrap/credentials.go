@@ -0,0 +1,183 @@
+package gitwrap
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/tychoish/gitgone/credentials"
+)
+
+// askPassScript answers git's and ssh's prompts for usernames,
+// passwords and key passphrases from the environment, so that
+// secrets never appear in the arguments of a process.
+const askPassScript = `#!/bin/sh
+case "$1" in
+	Username*) printf '%s\n' "$GITGONE_ASKPASS_USERNAME" ;;
+	*) printf '%s\n' "$GITGONE_ASKPASS_PASSWORD" ;;
+esac
+`
+
+func (self *repository) SetCredentials(provider credentials.Provider) {
+	self.credentials = provider
+}
+
+// remoteURL returns the URL for a named remote. Values that are not
+// the names of remotes are assumed to be URLs already.
+func (self *repository) remoteURL(remote string) string {
+	output, err := self.runGitCommand("remote", "get-url", remote)
+	if err != nil {
+		return remote
+	}
+
+	return output[0]
+}
+
+// upstreamRemote returns the remote that the current branch fetches
+// from, which is where "git fetch" without arguments fetches from,
+// falling back to "origin" as git does.
+func (self *repository) upstreamRemote() string {
+	branch := self.Branch()
+	if branch != "" {
+		remote, err := self.runGitCommand("config", "--get", "branch."+branch+".remote")
+		if err == nil && remote[0] != "" {
+			return remote[0]
+		}
+	}
+
+	return "origin"
+}
+
+// runNetworkGitCommand runs a git command that communicates with the
+// remote, which may be a remote name or a URL, using the credentials
+// configured for the repository.
+func (self *repository) runNetworkGitCommand(remote string, args ...string) ([]string, error) {
+	cmd := self.progressCommand(args...)
+
+	cleanup, err := self.authenticate(cmd, remote)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	return self.runProgressCommand(cmd)
+}
+
+// outputNetworkGitCommand is the equivalent of outputGitCommand for
+// commands that communicate with a remote, and also returns standard
+// error for use in error messages.
+func (self *repository) outputNetworkGitCommand(remote string, args ...string) ([]byte, string, error) {
+	cmd := self.progressCommand(args...)
+
+	cleanup, err := self.authenticate(cmd, remote)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cleanup()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	err = self.streamProgressCommand(cmd, stdout, stderr)
+
+	return stdout.Bytes(), strings.TrimSpace(stderr.String()), err
+}
+
+// checkNetworkGitCommand is the equivalent of checkGitCommand for
+// commands that communicate with a remote.
+func (self *repository) checkNetworkGitCommand(remote string, args ...string) error {
+	output, err := self.runNetworkGitCommand(remote, args...)
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("git %s: %s", args[0], strings.Join(output, "\n"))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// authenticate sets up the environment of a command that talks to a
+// remote. The returned function removes any temporary files and must
+// be called once the command completes.
+func (self *repository) authenticate(cmd *exec.Cmd, remote string) (func(), error) {
+	noop := func() {}
+	if self.credentials == nil {
+		return noop, nil
+	}
+
+	remoteURL := self.remoteURL(remote)
+
+	var username string
+	if u, err := credentials.ParseURL(remoteURL); err == nil && u.User != nil {
+		username = u.User.Username()
+	}
+
+	cred, err := self.credentials.Credentials(remoteURL, username)
+	if err == credentials.ErrNoCredentials || (err == nil && cred == nil) {
+		return noop, nil
+	} else if err != nil {
+		return noop, err
+	}
+
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var secret string
+
+	switch cred.Kind {
+	case credentials.Ambient:
+		return noop, nil
+	case credentials.Key:
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -l %s",
+			shellQuote(cred.PrivateKey), shellQuote(cred.Username)))
+		secret = cred.Passphrase
+	case credentials.Agent:
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -l %s", shellQuote(cred.Username)))
+	case credentials.Plaintext:
+		// disable configured credential helpers, which git
+		// would otherwise consult before asking for a password
+		env = append(env, "GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=credential.helper", "GIT_CONFIG_VALUE_0=")
+		secret = cred.Password
+	default:
+		return noop, fmt.Errorf("unsupported credential kind '%s'", cred.Kind)
+	}
+
+	cleanup := noop
+	if secret != "" {
+		script, err := ioutil.TempFile("", "gitgone-askpass-")
+		if err != nil {
+			return noop, err
+		}
+		cleanup = func() { os.Remove(script.Name()) }
+
+		_, err = script.WriteString(askPassScript)
+		if err == nil {
+			err = script.Chmod(0700)
+		}
+		if closeErr := script.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return noop, err
+		}
+
+		env = append(env,
+			"GIT_ASKPASS="+script.Name(),
+			"SSH_ASKPASS="+script.Name(),
+			"SSH_ASKPASS_REQUIRE=force",
+			"GITGONE_ASKPASS_USERNAME="+cred.Username,
+			"GITGONE_ASKPASS_PASSWORD="+secret)
+	}
+
+	cmd.Env = env
+
+	return cleanup, nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,91 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/credentials"
+	. "gopkg.in/check.v1"
+)
+
+// CredentialsSuite serves a bare repository with "git http-backend"
+// behind HTTP basic authentication, as a stand-in for an
+// authenticated remote.
+type CredentialsSuite struct {
+	dir    string
+	server *httptest.Server
+}
+
+var _ = Suite(&CredentialsSuite{})
+
+func (s *CredentialsSuite) SetUpSuite(c *C) {
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		c.Skip("git is not available")
+	}
+	backend := filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend")
+	if _, err = os.Stat(backend); err != nil {
+		c.Skip("git-http-backend is not available")
+	}
+
+	s.dir, err = ioutil.TempDir("", "gitgone-gitwrap-credentials-")
+	c.Assert(err, IsNil)
+
+	upstream := NewRepository(filepath.Join(s.dir, "upstream.git"))
+	c.Assert(upstream.Init(true, "master", ""), IsNil)
+	c.Assert(upstream.checkGitCommand("config", "http.receivepack", "true"), IsNil)
+
+	handler := &cgi.Handler{
+		Path: backend,
+		Env:  []string{"GIT_PROJECT_ROOT=" + s.dir, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="gitgone"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+func (s *CredentialsSuite) TearDownSuite(c *C) {
+	if s.server != nil {
+		s.server.Close()
+	}
+	os.RemoveAll(s.dir)
+}
+
+func (s *CredentialsSuite) TestPushAndCloneWithPassword(c *C) {
+	remote := s.server.URL + "/upstream.git"
+
+	local := newTestRepository(c)
+	c.Assert(ioutil.WriteFile(filepath.Join(local.path, "README"), []byte("hello\n"), 0644), IsNil)
+	c.Assert(local.Stage("README"), IsNil)
+	c.Assert(local.Commit("initial commit"), IsNil)
+	c.Assert(local.checkGitCommand("remote", "add", "origin", remote), IsNil)
+
+	c.Logf("pushing with the wrong password fails, rather than prompting")
+	local.SetCredentials(credentials.Password("alice", "wrong"))
+	c.Assert(local.Push("origin", "master"), NotNil)
+
+	local.SetCredentials(credentials.Password("alice", "secret"))
+	c.Assert(local.Push("origin", "master"), IsNil)
+	c.Assert(local.Fetch("all"), IsNil)
+
+	clone := NewRepository(filepath.Join(s.dir, "clone"))
+	clone.SetCredentials(credentials.Password("alice", "secret"))
+	c.Assert(clone.Clone(remote, "master"), IsNil)
+
+	contents, err := ioutil.ReadFile(filepath.Join(clone.path, "README"))
+	c.Assert(err, IsNil)
+	c.Assert(string(contents), Equals, "hello\n")
+}
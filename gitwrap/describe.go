@@ -0,0 +1,70 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) Describe(rev string, opts operations.DescribeOptions) (*operations.Description, error) {
+	args := []string{"describe", "--long", "--abbrev=" + strconv.Itoa(opts.AbbrevLength())}
+	if opts.Tags {
+		args = append(args, "--tags")
+	}
+	for _, pattern := range opts.Match {
+		args = append(args, "--match", pattern)
+	}
+	for _, pattern := range opts.Exclude {
+		args = append(args, "--exclude", pattern)
+	}
+
+	if opts.Dirty {
+		if rev != "" && rev != "HEAD" {
+			return nil, fmt.Errorf("cannot check the working tree when describing '%s'", rev)
+		}
+		args = append(args, "--dirty=-dirty")
+	} else if rev != "" {
+		args = append(args, rev)
+	}
+
+	output, err := self.runGitCommand(args...)
+	if err != nil {
+		message := strings.Join(output, "\n")
+		if strings.Contains(message, "No names found") || strings.Contains(message, "No tags can describe") {
+			return nil, operations.ErrNoDescription
+		}
+
+		return nil, fmt.Errorf("could not describe %s: %s", rev, message)
+	}
+
+	return parseDescription(output[0])
+}
+
+// parseDescription parses the output of "git describe --long", from
+// the right, since tag names may contain dashes.
+func parseDescription(value string) (*operations.Description, error) {
+	desc := &operations.Description{}
+
+	if strings.HasSuffix(value, "-dirty") {
+		desc.Dirty = true
+		value = strings.TrimSuffix(value, "-dirty")
+	}
+
+	parts := strings.Split(value, "-")
+	if len(parts) < 3 || !strings.HasPrefix(parts[len(parts)-1], "g") {
+		return nil, fmt.Errorf("malformed description '%s'", value)
+	}
+
+	distance, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed description '%s'", value)
+	}
+
+	desc.Tag = strings.Join(parts[:len(parts)-2], "-")
+	desc.Distance = distance
+	desc.Commit = strings.TrimPrefix(parts[len(parts)-1], "g")
+
+	return desc, nil
+}
@@ -0,0 +1,100 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type DescribeSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&DescribeSuite{})
+
+func (s *DescribeSuite) SetUpSuite(c *C) {
+	s.repo = newTestRepository(c)
+
+	c.Assert(ioutil.WriteFile(filepath.Join(s.repo.path, "VERSION"), []byte("1"), 0644), IsNil)
+	c.Assert(s.repo.Stage("VERSION"), IsNil)
+	c.Assert(s.repo.Commit("first"), IsNil)
+	c.Assert(s.repo.CreateTag("v1.2.0-rc.1", "", "release candidate", false), IsNil)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "second"), IsNil)
+	c.Assert(s.repo.CreateTag("build-7", "", "", false), IsNil)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "third"), IsNil)
+}
+
+func (s *DescribeSuite) TestDescribe(c *C) {
+	head, err := s.repo.getRef("HEAD")
+	c.Assert(err, IsNil)
+
+	desc, err := s.repo.Describe("", operations.DescribeOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(desc.Tag, Equals, "v1.2.0-rc.1")
+	c.Assert(desc.Distance, Equals, 2)
+	c.Assert(desc.Commit, Equals, head[:7])
+	c.Assert(desc.String(), Equals, "v1.2.0-rc.1-2-g"+head[:7])
+
+	desc, err = s.repo.Describe("HEAD", operations.DescribeOptions{Tags: true, Abbrev: 10})
+	c.Assert(err, IsNil)
+	c.Assert(desc.Tag, Equals, "build-7")
+	c.Assert(desc.Distance, Equals, 1)
+	c.Assert(desc.Commit, Equals, head[:10])
+
+	// git clamps abbreviations longer than a full id.
+	desc, err = s.repo.Describe("HEAD", operations.DescribeOptions{Tags: true, Abbrev: 50})
+	c.Assert(err, IsNil)
+	c.Assert(desc.Commit, Equals, head)
+
+	desc, err = s.repo.Describe("HEAD~1", operations.DescribeOptions{Tags: true})
+	c.Assert(err, IsNil)
+	c.Assert(desc.String(), Equals, "build-7")
+
+	desc, err = s.repo.Describe("", operations.DescribeOptions{Tags: true, Exclude: []string{"build-*"}})
+	c.Assert(err, IsNil)
+	c.Assert(desc.Tag, Equals, "v1.2.0-rc.1")
+
+	_, err = s.repo.Describe("", operations.DescribeOptions{Match: []string{"release-*"}})
+	c.Assert(err, Equals, operations.ErrNoDescription)
+
+	_, err = s.repo.Describe("HEAD~1", operations.DescribeOptions{Dirty: true})
+	c.Assert(err, NotNil)
+
+	c.Assert(ioutil.WriteFile(filepath.Join(s.repo.path, "VERSION"), []byte("2"), 0644), IsNil)
+	defer s.repo.checkGitCommand("checkout", "VERSION")
+
+	desc, err = s.repo.Describe("", operations.DescribeOptions{Dirty: true})
+	c.Assert(err, IsNil)
+	c.Assert(desc.Dirty, Equals, true)
+	c.Assert(desc.String(), Equals, "v1.2.0-rc.1-2-g"+head[:7]+"-dirty")
+}
+
+func (s *DescribeSuite) TestVersions(c *C) {
+	version, err := operations.ParseVersion("v1.2.0-rc.1+linux")
+	c.Assert(err, IsNil)
+	c.Assert(version.Major, Equals, 1)
+	c.Assert(version.Minor, Equals, 2)
+	c.Assert(version.Patch, Equals, 0)
+	c.Assert(version.Prerelease, Equals, "rc.1")
+	c.Assert(version.Build, Equals, "linux")
+	c.Assert(version.String(), Equals, "1.2.0-rc.1+linux")
+
+	version.Distance = 3
+	version.Commit = "abc1234"
+	version.Dirty = true
+	c.Assert(version.String(), Equals, "1.2.0-rc.1+linux.3.gabc1234.dirty")
+
+	for _, invalid := range []string{"1.2", "v01.2.3", "1.2.3-", "release"} {
+		_, err = operations.ParseVersion(invalid)
+		c.Assert(err, NotNil, Commentf(invalid))
+	}
+
+	desc, err := parseDescription("release-2-0-14-gabc1234-dirty")
+	c.Assert(err, IsNil)
+	c.Assert(desc.Tag, Equals, "release-2-0")
+	c.Assert(desc.Distance, Equals, 14)
+	c.Assert(desc.Commit, Equals, "abc1234")
+	c.Assert(desc.Dirty, Equals, true)
+}
@@ -0,0 +1,130 @@
+package gitwrap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) Fetch(remote string) error {
+	_, err := self.FetchWithOptions(operations.FetchOptions{Remote: remote})
+	return err
+}
+
+func (self *repository) FetchWithOptions(opts operations.FetchOptions) (*operations.FetchResult, error) {
+	remotes := []string{opts.Remote}
+	if opts.Remote == "all" {
+		// fetch each remote individually, rather than with
+		// --all, so that updates can be attributed to remotes
+		// and credentials resolved for each.
+		output, err := self.runGitCommand("remote")
+		if err != nil {
+			return nil, fmt.Errorf("problem listing remotes: %s", strings.Join(output, "\n"))
+		}
+		remotes = output
+	}
+
+	result := &operations.FetchResult{}
+	for _, remote := range remotes {
+		if remote == "" {
+			continue
+		}
+
+		updates, err := self.fetchRemote(remote, opts)
+		result.Updates = append(result.Updates, updates...)
+		if err != nil {
+			self.state = states.IncompleteOperation
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (self *repository) fetchRemote(remote string, opts operations.FetchOptions) ([]*operations.RefUpdate, error) {
+	args := []string{"fetch"}
+	if opts.Prune {
+		args = append(args, "--prune")
+	}
+	switch opts.Tags {
+	case operations.TagsAll:
+		args = append(args, "--tags")
+	case operations.TagsNone:
+		args = append(args, "--no-tags")
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	args = append(args, remote)
+	args = append(args, opts.Refspecs...)
+
+	before, err := self.refSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	err = self.checkNetworkGitCommand(remote, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := self.refSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return self.diffRefSnapshots(remote, before, after), nil
+}
+
+// refSnapshot maps every ref in the repository, other than symbolic
+// refs, to the object it points to.
+func (self *repository) refSnapshot() (map[string]string, error) {
+	output, err := self.outputGitCommand("for-each-ref", "--format=%(objectname) %(refname) %(symref)")
+	if err != nil {
+		return nil, fmt.Errorf("problem listing refs: %s", err)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, " ")
+		if len(parts) == 3 && parts[2] == "" {
+			refs[parts[1]] = parts[0]
+		}
+	}
+
+	return refs, nil
+}
+
+// diffRefSnapshots returns the updates between two snapshots, sorted
+// by ref name. Updates where the old commit is not an ancestor of the
+// new one are forced.
+func (self *repository) diffRefSnapshots(remote string, before, after map[string]string) []*operations.RefUpdate {
+	var updates []*operations.RefUpdate
+
+	for ref, newSha := range after {
+		oldSha, ok := before[ref]
+		if ok && oldSha == newSha {
+			continue
+		}
+
+		update := &operations.RefUpdate{Remote: remote, Ref: ref, Old: oldSha, New: newSha}
+		if ok {
+			update.Forced = self.checkGitCommand("merge-base", "--is-ancestor", oldSha, newSha) != nil
+		}
+		updates = append(updates, update)
+	}
+
+	for ref, oldSha := range before {
+		if _, ok := after[ref]; !ok {
+			updates = append(updates, &operations.RefUpdate{Remote: remote, Ref: ref, Old: oldSha})
+		}
+	}
+
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Ref < updates[j].Ref })
+
+	return updates
+}
@@ -0,0 +1,152 @@
+package gitwrap
+
+import (
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type FetchSuite struct {
+	upstream *repository
+	repo     *repository
+}
+
+var _ = Suite(&FetchSuite{})
+
+func (s *FetchSuite) SetUpTest(c *C) {
+	s.upstream = newTestRepository(c)
+	s.commit(c, "first")
+	c.Assert(s.upstream.checkGitCommand("branch", "topic"), IsNil)
+
+	s.repo = NewRepository(filepath.Join(c.MkDir(), "clone"))
+	c.Assert(s.repo.Clone(s.upstream.path, "master"), IsNil)
+}
+
+func (s *FetchSuite) commit(c *C, message string) {
+	c.Assert(s.upstream.checkGitCommand("commit", "--allow-empty", "-m", message), IsNil)
+}
+
+func (s *FetchSuite) ref(c *C, repo *repository, name string) string {
+	sha, err := repo.getRef(name)
+	c.Assert(err, IsNil)
+	return sha
+}
+
+func (s *FetchSuite) update(result *operations.FetchResult, ref string) *operations.RefUpdate {
+	for _, update := range result.Updates {
+		if update.Ref == ref {
+			return update
+		}
+	}
+	return nil
+}
+
+func (s *FetchSuite) TestNewAndUpdatedRefs(c *C) {
+	before := s.ref(c, s.repo, "refs/remotes/origin/master")
+	s.commit(c, "second")
+	c.Assert(s.upstream.checkGitCommand("branch", "feature"), IsNil)
+
+	result, err := s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin"})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 2)
+
+	created := result.Updates[0]
+	c.Assert(created.Ref, Equals, "refs/remotes/origin/feature")
+	c.Assert(created.Remote, Equals, "origin")
+	c.Assert(created.IsCreated(), Equals, true)
+	c.Assert(created.New, Equals, s.ref(c, s.upstream, "feature"))
+
+	updated := result.Updates[1]
+	c.Assert(updated.Ref, Equals, "refs/remotes/origin/master")
+	c.Assert(updated.IsCreated(), Equals, false)
+	c.Assert(updated.Forced, Equals, false)
+	c.Assert(updated.Old, Equals, before)
+	c.Assert(updated.New, Equals, s.ref(c, s.upstream, "master"))
+
+	c.Logf("fetching again changes nothing")
+	result, err = s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin"})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 0)
+}
+
+func (s *FetchSuite) TestForcedUpdate(c *C) {
+	c.Assert(s.upstream.checkGitCommand("checkout", "--quiet", "topic"), IsNil)
+	s.commit(c, "topic")
+	c.Assert(s.repo.Fetch("origin"), IsNil)
+
+	c.Assert(s.upstream.checkGitCommand("commit", "--amend", "--allow-empty", "-m", "rewritten"), IsNil)
+	result, err := s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin"})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 1)
+	c.Assert(result.Updates[0].Ref, Equals, "refs/remotes/origin/topic")
+	c.Assert(result.Updates[0].Forced, Equals, true)
+}
+
+func (s *FetchSuite) TestPrune(c *C) {
+	c.Assert(s.upstream.checkGitCommand("branch", "-D", "topic"), IsNil)
+
+	result, err := s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin"})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 0)
+
+	result, err = s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin", Prune: true})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 1)
+	c.Assert(result.Updates[0].Ref, Equals, "refs/remotes/origin/topic")
+	c.Assert(result.Updates[0].IsDeleted(), Equals, true)
+	c.Assert(result.Updates[0].Old, Equals, s.ref(c, s.upstream, "master"))
+}
+
+func (s *FetchSuite) TestTags(c *C) {
+	// "reachable" points into master's history, while "detached"
+	// points at a commit that no branch contains.
+	s.commit(c, "tagged")
+	c.Assert(s.upstream.checkGitCommand("tag", "reachable"), IsNil)
+	c.Assert(s.upstream.checkGitCommand("checkout", "--quiet", "--detach"), IsNil)
+	s.commit(c, "detached")
+	c.Assert(s.upstream.checkGitCommand("tag", "detached"), IsNil)
+	c.Assert(s.upstream.checkGitCommand("checkout", "--quiet", "master"), IsNil)
+
+	result, err := s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin", Tags: operations.TagsNone})
+	c.Assert(err, IsNil)
+	c.Assert(s.update(result, "refs/remotes/origin/master"), NotNil)
+	c.Assert(s.update(result, "refs/tags/reachable"), IsNil)
+	c.Assert(s.update(result, "refs/tags/detached"), IsNil)
+
+	result, err = s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin"})
+	c.Assert(err, IsNil)
+	c.Assert(s.update(result, "refs/tags/reachable").IsCreated(), Equals, true)
+	c.Assert(s.update(result, "refs/tags/detached"), IsNil)
+
+	result, err = s.repo.FetchWithOptions(operations.FetchOptions{Remote: "origin", Tags: operations.TagsAll})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 1)
+	c.Assert(result.Updates[0].Ref, Equals, "refs/tags/detached")
+	c.Assert(result.Updates[0].New, Equals, s.ref(c, s.upstream, "refs/tags/detached"))
+}
+
+func (s *FetchSuite) TestRefspecsAndAllRemotes(c *C) {
+	result, err := s.repo.FetchWithOptions(operations.FetchOptions{
+		Remote:   "origin",
+		Refspecs: []string{"refs/heads/topic:refs/heads/imported"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 1)
+	c.Assert(result.Updates[0].Ref, Equals, "refs/heads/imported")
+	c.Assert(result.Updates[0].IsCreated(), Equals, true)
+
+	c.Assert(s.repo.checkGitCommand("remote", "add", "mirror", s.upstream.path), IsNil)
+	s.commit(c, "second")
+
+	result, err = s.repo.FetchWithOptions(operations.FetchOptions{Remote: "all"})
+	c.Assert(err, IsNil)
+	c.Assert(s.update(result, "refs/remotes/origin/master").Remote, Equals, "origin")
+	c.Assert(s.update(result, "refs/remotes/mirror/master").Remote, Equals, "mirror")
+	c.Assert(s.update(result, "refs/remotes/mirror/topic").IsCreated(), Equals, true)
+}
+
+func (s *FetchSuite) TestMissingRemote(c *C) {
+	_, err := s.repo.FetchWithOptions(operations.FetchOptions{Remote: "missing"})
+	c.Assert(err, NotNil)
+}
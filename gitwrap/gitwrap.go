@@ -1,13 +1,19 @@
 package gitwrap
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tychoish/gitgone/credentials"
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/signing"
 	"github.com/tychoish/gitgone/states"
 	"github.com/tychoish/grip"
 )
@@ -19,7 +25,13 @@ type repository struct {
 	exists bool
 	state  states.RepositoryState
 
-	branches map[string]bool
+	branches          map[string]bool
+	recurseSubmodules bool
+	credentials       credentials.Provider
+	progress          operations.ProgressFunc
+	signer            signing.Signer
+	verifier          signing.Verifier
+	skipHooks         bool
 }
 
 func NewRepository(path string) *repository {
@@ -52,8 +64,14 @@ func NewRepository(path string) *repository {
 }
 
 func (self *repository) updateBranchTracking() {
-	branch, _ := self.runGitCommand("symbolic-ref", "--short", "HEAD")
-	self.branch = strings.Join(branch, "\n")
+	// symbolic-ref fails, and reports the error on its output,
+	// when HEAD is detached, in which case there is no branch.
+	branch, err := self.runGitCommand("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		self.branch = ""
+	} else {
+		self.branch = strings.Join(branch, "\n")
+	}
 
 	branches, _ := self.runGitCommand("branch", "--list", "--no-color")
 	for _, b := range branches {
@@ -94,34 +112,119 @@ func (self *repository) CreateBranch(name, starting string) error {
 	return err
 }
 
-func (self *repository) runGitCommand(args ...string) ([]string, error) {
+// gitCommand returns a git command that runs in the repository, with
+// hooks disabled when the repository skips hooks.
+func (self *repository) gitCommand(args ...string) *exec.Cmd {
+	if self.skipHooks {
+		args = append([]string{"-c", "core.hooksPath=" + os.DevNull}, args...)
+	}
+
 	cmd := exec.Command("git", args...)
 	cmd.Dir = self.path
 
+	return cmd
+}
+
+func (self *repository) runGitCommand(args ...string) ([]string, error) {
+	cmd := self.gitCommand(args...)
+
+	output, err := cmd.CombinedOutput()
+
+	return strings.Split(strings.Trim(string(output), " \t\n\r"), "\n"), err
+}
+
+// outputGitCommand returns the unmodified standard output of a git
+// command, for commands whose output is NUL delimited or otherwise
+// sensitive to whitespace.
+func (self *repository) outputGitCommand(args ...string) ([]byte, error) {
+	cmd := self.gitCommand(args...)
+
+	return cmd.Output()
+}
+
+// commandError returns the standard error output of a failed
+// command, when it was captured, or the error itself.
+func commandError(err error) string {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+
+	return err.Error()
+}
+
+// inputGitCommand runs a git command that reads its input from
+// standard input.
+func (self *repository) inputGitCommand(input []byte, args ...string) ([]string, error) {
+	cmd := self.gitCommand(args...)
+	cmd.Stdin = bytes.NewReader(input)
+
 	output, err := cmd.CombinedOutput()
 
 	return strings.Split(strings.Trim(string(output), " \t\n\r"), "\n"), err
 }
 
 func (self *repository) checkGitCommand(args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = self.path
+	cmd := self.gitCommand(args...)
 
 	return cmd.Run()
 }
 
-func (self *repository) Clone(remote, branch string) (err error) {
+func (self *repository) Clone(remote, branch string) error {
+	return self.CloneWithOptions(remote, operations.CloneOptions{Branch: branch})
+}
+
+func (self *repository) CloneWithOptions(remote string, opts operations.CloneOptions) (err error) {
 	if _, err = os.Stat(self.path); err != nil && self.exists {
 		return fmt.Errorf("could not clone %s (%s) into %s, because repository exists",
-			remote, branch, self.path)
+			remote, opts.Branch, self.path)
 	}
 
-	err = self.checkGitCommand("clone", remote, "--branch", branch, filepath.Dir(self.path))
-	if err == nil {
-		self.exists = true
+	args := []string{"clone", "--quiet"}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if !opts.ShallowSince.IsZero() {
+		args = append(args, "--shallow-since", opts.ShallowSince.Format(time.RFC3339))
+	}
+	if opts.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter", opts.Filter)
+	}
+	if opts.NoCheckout {
+		args = append(args, "--no-checkout")
+	}
+	if opts.Mirror {
+		args = append(args, "--mirror")
+	}
+	for _, ref := range opts.References {
+		args = append(args, "--reference", ref)
+	}
+	if self.recurseSubmodules && !opts.NoCheckout && !opts.Mirror {
+		args = append(args, "--recurse-submodules")
 	}
+	args = append(args, remote, ".")
 
-	return err
+	if err = os.MkdirAll(self.path, 0755); err != nil {
+		return err
+	}
+
+	output, err := self.runNetworkGitCommand(remote, args...)
+	if err != nil {
+		self.state = states.FailedOperation
+		return fmt.Errorf("problem cloning %s into %s: %s", remote, self.path,
+			strings.Join(output, "\n"))
+	}
+
+	self.exists = true
+	self.bare = opts.Mirror
+	self.updateBranchTracking()
+
+	return nil
 }
 
 func (self *repository) IsBare() bool {
@@ -137,12 +240,13 @@ func (self *repository) Checkout(ref string) error {
 		return fmt.Errorf("cannot modify the working tree of this repository")
 	}
 
-	err := self.checkGitCommand("checkout", ref)
+	_, err := self.runProgressCommand(self.progressCommand("checkout", ref))
 	if err != nil {
 		self.state = states.UnresolvedOperation
+		return err
 	}
 
-	return err
+	return self.updateRecursiveSubmodules()
 }
 
 func (self *repository) RemoveBranch(branch string) error {
@@ -184,28 +288,43 @@ func (self *repository) Reset(ref string, hard bool) error {
 
 }
 
-func (self *repository) Fetch(remote string) error {
-	if remote == "all" {
-		remote = "--all"
+func (self *repository) Deepen(commits int) error {
+	remote := self.upstreamRemote()
+	return self.checkNetworkGitCommand(remote, "fetch", "--deepen", strconv.Itoa(commits), remote)
+}
+
+func (self *repository) Unshallow() error {
+	shallow, err := self.runGitCommand("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return err
+	}
+
+	if shallow[0] != "true" {
+		return nil
 	}
 
-	return self.checkGitCommand("fetch", remote)
+	remote := self.upstreamRemote()
+	return self.checkNetworkGitCommand(remote, "fetch", "--unshallow", remote)
 }
 
 func (self *repository) Pull(remote string, branch string) error {
-	err := self.checkGitCommand("pull", remote, branch)
+	err := self.checkNetworkGitCommand(remote, "pull", remote, branch)
 	if err != nil {
 		self.state = states.UnresolvedOperation
+		return err
 	}
-	return err
+
+	return self.updateRecursiveSubmodules()
 }
 
 func (self *repository) PullRebase(remote string, branch string) error {
-	err := self.checkGitCommand("pull", "--rebase", remote, branch)
+	err := self.checkNetworkGitCommand(remote, "pull", "--rebase", remote, branch)
 	if err != nil {
 		self.state = states.UnresolvedOperation
+		return err
 	}
-	return err
+
+	return self.updateRecursiveSubmodules()
 }
 
 func (self *repository) CherryPick(commits ...string) error {
@@ -267,80 +386,41 @@ func (self *repository) StageAllPath(path string) {
 }
 
 func (self *repository) Commit(message string) error {
+	if self.signer != nil {
+		return self.createSignedCommit(message, false)
+	}
+
 	return self.checkGitCommand("commit", "--message", message)
 }
 
 func (self *repository) CommitAll(message string) error {
-	return self.checkGitCommand("commit", "--all", "--message", message)
-}
-
-func (self *repository) Amend(message string) error {
-	return self.checkGitCommand("commit", "--amend", "--message", message)
-}
+	if self.signer != nil {
+		if err := self.checkGitCommand("add", "--update"); err != nil {
+			return err
+		}
 
-func (self *repository) AmendAll(message string) error {
-	return self.checkGitCommand("commit", "--amend", "--all", "--message", message)
-}
+		return self.createSignedCommit(message, false)
+	}
 
-func (self *repository) Push(remote, branch string) error {
-	return self.checkGitCommand("push", remote, branch)
+	return self.checkGitCommand("commit", "--all", "--message", message)
 }
 
-func (self *repository) CreateTag(name, sha, message string, force bool) error {
-	if sha == "" {
-		sha = "HEAD"
-	}
-
-	if message == "" {
-		if force {
-			return self.checkGitCommand("tag", "--force", name, sha)
-		} else {
-			return self.checkGitCommand("tag", name, sha)
-		}
-	} else {
-		if force {
-			return self.checkGitCommand("tag", "--force", "--annotate",
-				"--message", message, name, sha)
-		} else {
-			return self.checkGitCommand("tag", "--annotate", "--message", message, name, sha)
-		}
+func (self *repository) Amend(message string) error {
+	if self.signer != nil {
+		return self.createSignedCommit(message, true)
 	}
-}
 
-func (self *repository) DeleteTag(name string) error {
-	return self.checkGitCommand("tag", "--delete", name)
+	return self.checkGitCommand("commit", "--amend", "--message", message)
 }
 
-func (self *repository) IsTagged(name, sha string, lightweight bool) bool {
-	var err error
-
-	if sha == "" || sha == "HEAD" {
-		sha, err = self.getRef("HEAD")
-		if err != nil {
-			return false
+func (self *repository) AmendAll(message string) error {
+	if self.signer != nil {
+		if err := self.checkGitCommand("add", "--update"); err != nil {
+			return err
 		}
-	}
-
-	if lightweight {
-		err = self.checkGitCommand("describe", "--tags", name)
-		return true
-	} else {
-		err = self.checkGitCommand("describe", name)
-	}
 
-	if err != nil {
-		grip.CatchError(err)
-		return false
+		return self.createSignedCommit(message, true)
 	}
 
-	taggedCommit, err := self.runGitCommand("ref-list", "--max-count", "1", sha)
-	if err != nil {
-		grip.CatchError(err)
-		return false
-	}
-
-	if taggedCommit[0] == sha {
-		return true
-	}
-	return false
+	return self.checkGitCommand("commit", "--amend", "--all", "--message", message)
 }
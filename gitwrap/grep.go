@@ -0,0 +1,78 @@
+package gitwrap
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) Grep(rev, pattern string, opts operations.GrepOptions) ([]*operations.GrepMatch, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	args := []string{"grep", "-z", "--line-number", "-I", "--no-color"}
+	if opts.FixedStrings {
+		args = append(args, "--fixed-strings")
+	} else {
+		// Perl compatible expressions agree with the regexp
+		// package for the patterns that it accepts, so reject
+		// the rest, such as backreferences, as gitrect does.
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+		args = append(args, "--perl-regexp")
+	}
+	if opts.IgnoreCase {
+		args = append(args, "--ignore-case")
+	}
+	args = append(args, "-e", pattern, rev, "--")
+	args = append(args, opts.Pathspecs...)
+
+	output, err := self.outputGitCommand(args...)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			// no matches
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("could not search %s: %s", rev, commandError(err))
+	}
+
+	return parseGrepOutput(output, rev+":")
+}
+
+// parseGrepOutput converts the output of "git grep -z --line-number",
+// where each path is prefixed by the revision searched.
+func parseGrepOutput(output []byte, prefix string) ([]*operations.GrepMatch, error) {
+	var matches []*operations.GrepMatch
+
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		fields := bytes.SplitN(line, []byte{0}, 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed grep output: %q", line)
+		}
+
+		number, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("malformed grep output: %q", line)
+		}
+
+		matches = append(matches, &operations.GrepMatch{
+			Path: strings.TrimPrefix(string(fields[0]), prefix),
+			Line: number,
+			Text: string(fields[2]),
+		})
+	}
+
+	return matches, nil
+}
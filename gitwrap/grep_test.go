@@ -0,0 +1,83 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+func (s *ParserSuite) TestGrepOutputParsing(c *C) {
+	matches, err := parseGrepOutput([]byte("v1.0:README\x002\x00a line: with colons\n"+
+		"v1.0:docs/v1.0:notes\x0010\x00\n"), "v1.0:")
+	c.Assert(err, IsNil)
+	c.Assert(matches, HasLen, 2)
+
+	c.Assert(matches[0].Path, Equals, "README")
+	c.Assert(matches[0].Line, Equals, 2)
+	c.Assert(matches[0].Text, Equals, "a line: with colons")
+
+	c.Assert(matches[1].Path, Equals, "docs/v1.0:notes")
+	c.Assert(matches[1].Line, Equals, 10)
+	c.Assert(matches[1].Text, Equals, "")
+
+	_, err = parseGrepOutput([]byte("README:2:text\n"), "HEAD:")
+	c.Assert(err, NotNil)
+}
+
+type GrepSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&GrepSuite{})
+
+func (s *GrepSuite) SetUpSuite(c *C) {
+	s.repo = newTestRepository(c)
+
+	files := map[string]string{
+		"README":       "version 1.2\nRelease notes\n",
+		"src/main.go":  "func main() {}\nconst version = 12\n",
+		"src/util.go":  "// versions (plural)\n",
+		"docs/a+b.txt": "a+b\naab\n",
+	}
+	for name, contents := range files {
+		fn := filepath.Join(s.repo.path, name)
+		c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fn, []byte(contents), 0644), IsNil)
+	}
+	c.Assert(s.repo.checkGitCommand("add", "."), IsNil)
+	c.Assert(s.repo.Commit("initial"), IsNil)
+}
+
+func (s *GrepSuite) grep(c *C, pattern string, opts operations.GrepOptions) []string {
+	matches, err := s.repo.Grep("", pattern, opts)
+	c.Assert(err, IsNil)
+
+	var out []string
+	for _, m := range matches {
+		out = append(out, m.Path+":"+m.Text)
+	}
+	return out
+}
+
+func (s *GrepSuite) TestRegexpSyntax(c *C) {
+	// patterns use the syntax of the regexp package, as they do
+	// in gitrect, including perl character classes.
+	c.Assert(s.grep(c, `\d+\.\d+`, operations.GrepOptions{}), DeepEquals, []string{"README:version 1.2"})
+	c.Assert(s.grep(c, `\bversion\b`, operations.GrepOptions{}), DeepEquals,
+		[]string{"README:version 1.2", "src/main.go:const version = 12"})
+	c.Assert(s.grep(c, `^release`, operations.GrepOptions{IgnoreCase: true}), DeepEquals, []string{"README:Release notes"})
+	c.Assert(s.grep(c, `a+b`, operations.GrepOptions{Pathspecs: []string{"docs"}}), DeepEquals,
+		[]string{"docs/a+b.txt:aab"})
+	c.Assert(s.grep(c, `a+b`, operations.GrepOptions{FixedStrings: true}), DeepEquals,
+		[]string{"docs/a+b.txt:a+b"})
+	c.Assert(s.grep(c, `missing`, operations.GrepOptions{}), HasLen, 0)
+
+	// backreferences and lookarounds are not part of the syntax.
+	_, err := s.repo.Grep("", `(a)\1`, operations.GrepOptions{})
+	c.Assert(err, NotNil)
+	_, err = s.repo.Grep("", `version(?= 1)`, operations.GrepOptions{})
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,16 @@
+package gitwrap
+
+import (
+	"github.com/tychoish/gitgone/config"
+	. "gopkg.in/check.v1"
+)
+
+// newTestRepository initializes a non-bare repository in a temporary
+// directory, with a committer identity so that tests can commit.
+func newTestRepository(c *C) *repository {
+	repo := NewRepository(c.MkDir())
+	c.Assert(repo.Init(false, "master", ""), IsNil)
+	c.Assert(repo.Config().Set(config.Local, "user.name", "Gitgone"), IsNil)
+	c.Assert(repo.Config().Set(config.Local, "user.email", "gitgone@example.com"), IsNil)
+	return repo
+}
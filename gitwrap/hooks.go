@@ -0,0 +1,49 @@
+package gitwrap
+
+import (
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/hooks"
+)
+
+// SetSkipHooks disables the repository's hook scripts for all
+// operations.
+func (self *repository) SetSkipHooks(skip bool) {
+	self.skipHooks = skip
+}
+
+// hookRunner returns a runner for the hooks directory that git uses,
+// which respects core.hooksPath.
+func (self *repository) hookRunner() *hooks.Runner {
+	runner := &hooks.Runner{WorkDir: self.path}
+
+	dir, err := self.runGitCommand("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return runner
+	}
+
+	runner.Dir = dir[0]
+	if !filepath.IsAbs(runner.Dir) {
+		runner.Dir = filepath.Join(self.path, runner.Dir)
+	}
+
+	return runner
+}
+
+// runHook runs a hook script for operations that git does not run
+// hooks for itself.
+func (self *repository) runHook(hook hooks.Type, stdin []byte, args ...string) error {
+	if self.skipHooks {
+		return nil
+	}
+
+	return self.hookRunner().Run(hook, stdin, args...)
+}
+
+func (self *repository) commitMessageHook(message string) (string, error) {
+	if self.skipHooks {
+		return message, nil
+	}
+
+	return self.hookRunner().CommitMessage(message)
+}
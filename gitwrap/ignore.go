@@ -0,0 +1,125 @@
+package gitwrap
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// IsIgnored reports whether each path is ignored, and the rule that
+// matched it. Like "git check-ignore", tracked files are never
+// ignored.
+func (self *repository) IsIgnored(paths ...string) ([]*operations.IgnoreResult, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	input := &bytes.Buffer{}
+	for _, path := range paths {
+		input.WriteString(path)
+		input.WriteByte(0)
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd := self.gitCommand("check-ignore", "--verbose", "--non-matching", "-z", "--stdin")
+	cmd.Stdin = input
+	cmd.Stderr = stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		// check-ignore exits 1 when no paths are ignored
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("could not check ignored paths: %s", strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	return parseCheckIgnore(output)
+}
+
+// parseCheckIgnore parses the output of "git check-ignore -v -z
+// --non-matching", which has four fields for each path: the source,
+// line number and pattern of the matching rule, which are empty when
+// no rule matches, and the path.
+func parseCheckIgnore(output []byte) ([]*operations.IgnoreResult, error) {
+	fields := strings.Split(string(output), "\x00")
+	if len(fields) > 0 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields)%4 != 0 {
+		return nil, fmt.Errorf("malformed check-ignore output")
+	}
+
+	var results []*operations.IgnoreResult
+	for i := 0; i < len(fields); i += 4 {
+		result := &operations.IgnoreResult{
+			Source:  fields[i],
+			Pattern: fields[i+2],
+			Path:    fields[i+3],
+		}
+
+		if result.Pattern != "" {
+			line, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("malformed check-ignore line number '%s'", fields[i+1])
+			}
+			result.Line = line
+			result.Ignored = !strings.HasPrefix(result.Pattern, "!")
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// AddIgnoreRule adds a rule to the .gitignore file at the top of the
+// working tree, or, for rules that should not be shared with other
+// clones, to the repository's info/exclude file.
+func (self *repository) AddIgnoreRule(rule string, local bool) error {
+	filename := filepath.Join(self.path, ".gitignore")
+
+	if local || self.bare {
+		output, err := self.runGitCommand("rev-parse", "--git-path", "info/exclude")
+		if err != nil {
+			return fmt.Errorf("could not find exclude file: %s", strings.Join(output, "\n"))
+		}
+
+		filename = output[0]
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(self.path, filename)
+		}
+	}
+
+	return operations.AppendIgnoreRule(filename, rule)
+}
+
+// Attributes returns the named gitattributes attributes of a path,
+// in order, or all of the attributes that apply to it when no names
+// are given.
+func (self *repository) Attributes(path string, names ...string) ([]*operations.Attribute, error) {
+	args := []string{"check-attr", "-z"}
+	if len(names) == 0 {
+		args = append(args, "--all")
+	} else {
+		args = append(args, names...)
+	}
+	args = append(args, "--", path)
+
+	output, err := self.outputGitCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not check attributes of %s: %s", path, commandError(err))
+	}
+
+	fields := strings.Split(string(output), "\x00")
+	var attrs []*operations.Attribute
+	for i := 0; i+2 < len(fields); i += 3 {
+		attrs = append(attrs, operations.ParseAttribute(fields[i+1], fields[i+2]))
+	}
+
+	return attrs, nil
+}
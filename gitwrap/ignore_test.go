@@ -0,0 +1,113 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type IgnoreSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&IgnoreSuite{})
+
+func (s *IgnoreSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c)
+
+	s.writeFile(c, ".gitignore", "*.log\n!keep.log\nbuild/\n")
+	s.writeFile(c, "sub/.gitignore", "# local secrets\nsecret\n")
+	s.writeFile(c, ".gitattributes", "*.bin binary\n*.txt text eol=lf diff=plain\ndocs/** -text\n")
+	s.writeFile(c, "docs/.gitattributes", "*.txt linguist-documentation\n")
+	s.writeFile(c, "tracked.log", "tracked\n")
+	c.Assert(s.repo.checkGitCommand("add", "--force", "."), IsNil)
+	c.Assert(s.repo.Commit("initial"), IsNil)
+}
+
+func (s *IgnoreSuite) writeFile(c *C, name, contents string) {
+	fn := filepath.Join(s.repo.path, name)
+	c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(fn, []byte(contents), 0644), IsNil)
+}
+
+func (s *IgnoreSuite) TestIsIgnored(c *C) {
+	paths := []string{"a.log", "keep.log", "build/out", "sub/secret", "sub/dir/secret", "ok.txt", "tracked.log"}
+	results, err := s.repo.IsIgnored(paths...)
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, len(paths))
+
+	expected := []operations.IgnoreResult{
+		{Path: "a.log", Ignored: true, Source: ".gitignore", Line: 1, Pattern: "*.log"},
+		{Path: "keep.log", Ignored: false, Source: ".gitignore", Line: 2, Pattern: "!keep.log"},
+		{Path: "build/out", Ignored: true, Source: ".gitignore", Line: 3, Pattern: "build/"},
+		{Path: "sub/secret", Ignored: true, Source: "sub/.gitignore", Line: 2, Pattern: "secret"},
+		{Path: "sub/dir/secret", Ignored: true, Source: "sub/.gitignore", Line: 2, Pattern: "secret"},
+		{Path: "ok.txt"},
+		{Path: "tracked.log"},
+	}
+	for idx, result := range results {
+		c.Assert(*result, DeepEquals, expected[idx])
+	}
+	c.Assert(results[5].IsMatched(), Equals, false)
+
+	// the shared rule parser agrees with git
+	var rules []*operations.IgnoreRule
+	rules = append(rules, operations.ParseIgnoreRules(".gitignore", "", []byte("*.log\n!keep.log\nbuild/\n"))...)
+	rules = append(rules, operations.ParseIgnoreRules("sub/.gitignore", "sub", []byte("# local secrets\nsecret\n"))...)
+	for _, result := range expected[:6] {
+		rule := operations.MatchIgnoreRules(rules, result.Path)
+		if result.Pattern == "" {
+			c.Assert(rule, IsNil)
+			continue
+		}
+		c.Assert(rule, NotNil, Commentf(result.Path))
+		c.Assert(rule.Pattern, Equals, result.Pattern)
+		c.Assert(rule.Line, Equals, result.Line)
+		c.Assert(rule.IsNegated(), Equals, !result.Ignored)
+	}
+}
+
+func (s *IgnoreSuite) TestAddIgnoreRule(c *C) {
+	c.Assert(s.repo.AddIgnoreRule("*.tmp", false), IsNil)
+	c.Assert(s.repo.AddIgnoreRule("scratch/", true), IsNil)
+
+	results, err := s.repo.IsIgnored("a.tmp", "scratch/notes")
+	c.Assert(err, IsNil)
+	c.Assert(results[0].Source, Equals, ".gitignore")
+	c.Assert(results[0].Line, Equals, 4)
+	c.Assert(results[1].Source, Equals, ".git/info/exclude")
+	c.Assert(results[1].Ignored, Equals, true)
+}
+
+func (s *IgnoreSuite) TestAttributes(c *C) {
+	rules := operations.ParseAttributeRules("", []byte("*.bin binary\n*.txt text eol=lf diff=plain\ndocs/** -text\n"))
+	rules = append(rules, operations.ParseAttributeRules("docs", []byte("*.txt linguist-documentation\n"))...)
+
+	for _, name := range []string{"a.txt", "b.bin", "docs/guide.txt", "docs/images/logo.png", "README"} {
+		attrs, err := s.repo.Attributes(name, "text", "eol", "diff", "binary", "linguist-documentation")
+		c.Assert(err, IsNil)
+
+		resolved := operations.ResolveAttributes(rules, name, "text", "eol", "diff", "binary", "linguist-documentation")
+		c.Assert(attrs, DeepEquals, resolved, Commentf(name))
+
+		all, err := s.repo.Attributes(name)
+		c.Assert(err, IsNil)
+		byName := map[string]string{}
+		for _, attr := range all {
+			byName[attr.Name] = attr.String()
+		}
+		resolvedByName := map[string]string{}
+		for _, attr := range operations.ResolveAttributes(rules, name) {
+			resolvedByName[attr.Name] = attr.String()
+		}
+		c.Assert(byName, DeepEquals, resolvedByName, Commentf(name))
+	}
+
+	attrs, err := s.repo.Attributes("docs/guide.txt", "text", "eol")
+	c.Assert(err, IsNil)
+	c.Assert(attrs[0].State, Equals, operations.AttributeUnset)
+	c.Assert(attrs[1].Value, Equals, "lf")
+}
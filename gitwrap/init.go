@@ -0,0 +1,50 @@
+package gitwrap
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) Init(bare bool, initialBranch, template string) error {
+	if self.exists {
+		return fmt.Errorf("cannot initialize repository at %s, because repository exists", self.path)
+	}
+
+	err := os.MkdirAll(self.path, 0755)
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	args := []string{"init", "--quiet"}
+	if bare {
+		args = append(args, "--bare")
+	}
+	if template != "" {
+		args = append(args, "--template", template)
+	}
+	args = append(args, ".")
+
+	output, err := self.runGitCommand(args...)
+	if err != nil {
+		self.state = states.FailedOperation
+		return fmt.Errorf("problem initializing repository at %s: %s", self.path, output)
+	}
+
+	if initialBranch != "" {
+		err = self.checkGitCommand("symbolic-ref", "HEAD", "refs/heads/"+initialBranch)
+		if err != nil {
+			self.state = states.IncompleteOperation
+			return err
+		}
+	}
+
+	self.exists = true
+	self.bare = bare
+	self.state = states.New
+	self.updateBranchTracking()
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/states"
+	. "gopkg.in/check.v1"
+)
+
+type InitSuite struct{}
+
+var _ = Suite(&InitSuite{})
+
+func (s *InitSuite) TestInit(c *C) {
+	repo := NewRepository(filepath.Join(c.MkDir(), "repo"))
+	c.Assert(repo.IsExists(), Equals, false)
+
+	c.Assert(repo.Init(false, "", ""), IsNil)
+	c.Assert(repo.IsExists(), Equals, true)
+	c.Assert(repo.IsBare(), Equals, false)
+	c.Assert(repo.state, Equals, states.New)
+
+	_, err := os.Stat(filepath.Join(repo.path, ".git", "HEAD"))
+	c.Assert(err, IsNil)
+
+	c.Logf("a repository cannot be initialized twice")
+	c.Assert(repo.Init(false, "", ""), NotNil)
+
+	c.Logf("reopening the repository detects that it exists")
+	reopened := NewRepository(repo.path)
+	c.Assert(reopened.IsExists(), Equals, true)
+	c.Assert(reopened.IsBare(), Equals, false)
+}
+
+func (s *InitSuite) TestInitBare(c *C) {
+	repo := NewRepository(filepath.Join(c.MkDir(), "repo.git"))
+	c.Assert(repo.Init(true, "", ""), IsNil)
+	c.Assert(repo.IsExists(), Equals, true)
+	c.Assert(repo.IsBare(), Equals, true)
+
+	_, err := os.Stat(filepath.Join(repo.path, "HEAD"))
+	c.Assert(err, IsNil)
+	_, err = os.Stat(filepath.Join(repo.path, ".git"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	reopened := NewRepository(repo.path)
+	c.Assert(reopened.IsExists(), Equals, true)
+	c.Assert(reopened.IsBare(), Equals, true)
+}
+
+func (s *InitSuite) TestInitialBranch(c *C) {
+	for _, bare := range []bool{false, true} {
+		repo := NewRepository(c.MkDir())
+		c.Assert(repo.Init(bare, "trunk", ""), IsNil)
+
+		head, err := repo.runGitCommand("symbolic-ref", "HEAD")
+		c.Assert(err, IsNil)
+		c.Assert(head[0], Equals, "refs/heads/trunk")
+		c.Assert(repo.Branch(), Equals, "trunk")
+
+		if !bare {
+			c.Assert(repo.checkGitCommand("-c", "user.name=Gitgone", "-c", "user.email=gitgone@example.com",
+				"commit", "--allow-empty", "-m", "first"), IsNil)
+			c.Assert(repo.BranchExists("trunk"), Equals, true)
+		}
+	}
+}
+
+func (s *InitSuite) TestInitTemplate(c *C) {
+	template := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(template, "info"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(template, "info", "exclude"), []byte("*.tmp\n"), 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(template, "description"), []byte("templated\n"), 0644), IsNil)
+
+	repo := NewRepository(c.MkDir())
+	c.Assert(repo.Init(false, "", template), IsNil)
+
+	data, err := ioutil.ReadFile(filepath.Join(repo.path, ".git", "description"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "templated\n")
+
+	c.Assert(ioutil.WriteFile(filepath.Join(repo.path, "scratch.tmp"), nil, 0644), IsNil)
+	ignored, err := repo.runGitCommand("check-ignore", "scratch.tmp")
+	c.Assert(err, IsNil)
+	c.Assert(ignored[0], Equals, "scratch.tmp")
+}
+
+func (s *InitSuite) TestInitMissingTemplate(c *C) {
+	repo := NewRepository(c.MkDir())
+	// git warns about, but does not reject, a missing template.
+	c.Assert(repo.Init(false, "", filepath.Join(c.MkDir(), "missing")), IsNil)
+	c.Assert(repo.IsExists(), Equals, true)
+}
@@ -0,0 +1,151 @@
+package gitwrap
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) Maintenance(opts operations.MaintenanceOptions) (*operations.MaintenanceResult, error) {
+	result := &operations.MaintenanceResult{}
+
+	before, err := self.objectStats()
+	if err != nil {
+		return nil, err
+	}
+	result.Before = before
+
+	var tasks [][]string
+	switch opts.GC {
+	case operations.GCAuto:
+		tasks = append(tasks, []string{"gc", "--quiet", "--auto"})
+	case operations.GCNormal:
+		tasks = append(tasks, []string{"gc", "--quiet"})
+	case operations.GCAggressive:
+		tasks = append(tasks, []string{"gc", "--quiet", "--aggressive"})
+	}
+	if opts.Repack {
+		tasks = append(tasks, []string{"repack", "-a", "-d", "--quiet"})
+	}
+	if opts.Prune {
+		tasks = append(tasks, []string{"prune", "--expire", opts.PruneBefore().Format(time.RFC3339)})
+	}
+	if opts.CommitGraph {
+		tasks = append(tasks, []string{"commit-graph", "write", "--reachable", "--no-progress"})
+	}
+
+	for _, args := range tasks {
+		output, err := self.runGitCommand(args...)
+		if err != nil {
+			self.state = states.IncompleteOperation
+			return result, fmt.Errorf("problem running %s: %s", args[0], strings.Join(output, "\n"))
+		}
+	}
+
+	if opts.Fsck {
+		output, err := self.runGitCommand("fsck", "--no-progress")
+		result.Findings = parseFsckOutput(output)
+
+		// fsck exits non-zero when it finds problems, which
+		// are reported as findings rather than errors.
+		if err != nil && len(result.Findings) == 0 {
+			return result, fmt.Errorf("problem running fsck: %s", strings.Join(output, "\n"))
+		}
+	}
+
+	result.After, err = self.objectStats()
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// objectStats collects object counts from "git count-objects", which
+// reports sizes in KiB.
+func (self *repository) objectStats() (operations.ObjectStats, error) {
+	stats := operations.ObjectStats{}
+
+	output, err := self.runGitCommand("count-objects", "-v")
+	if err != nil {
+		return stats, fmt.Errorf("problem counting objects: %s", strings.Join(output, "\n"))
+	}
+
+	for _, line := range output {
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		value, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch parts[0] {
+		case "count":
+			stats.LooseObjects = int(value)
+		case "size":
+			stats.LooseSize = value * 1024
+		case "in-pack":
+			stats.PackedObjects = int(value)
+		case "packs":
+			stats.Packs = int(value)
+		case "size-pack":
+			stats.PackSize = value * 1024
+		}
+	}
+
+	return stats, nil
+}
+
+var (
+	fsckObjectLine = regexp.MustCompile(`^(dangling|missing) (commit|tree|blob|tag) ([0-9a-f]{40,64})$`)
+	fsckObjectID   = regexp.MustCompile(`\b[0-9a-f]{40,64}\b`)
+	fsckCorruption = regexp.MustCompile(`corrupt|mismatch|unable to unpack|inflate|is empty|bad object`)
+)
+
+func parseFsckOutput(lines []string) []*operations.FsckFinding {
+	var findings []*operations.FsckFinding
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if match := fsckObjectLine.FindStringSubmatch(line); match != nil {
+			kind := operations.FsckDangling
+			if match[1] == "missing" {
+				kind = operations.FsckMissing
+			}
+
+			findings = append(findings, &operations.FsckFinding{
+				Kind:       kind,
+				ObjectType: match[2],
+				Object:     match[3],
+				Message:    line,
+			})
+			continue
+		}
+
+		if !strings.HasPrefix(line, "error: ") {
+			// broken links are followed by a missing
+			// object line, and other output is informational
+			continue
+		}
+
+		message := strings.TrimPrefix(line, "error: ")
+		finding := &operations.FsckFinding{Kind: operations.FsckError, Message: message}
+		if fsckCorruption.MatchString(message) {
+			finding.Kind = operations.FsckCorrupt
+			finding.Object = fsckObjectID.FindString(message)
+		}
+
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
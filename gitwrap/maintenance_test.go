@@ -0,0 +1,88 @@
+package gitwrap
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type MaintenanceSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&MaintenanceSuite{})
+
+func (s *MaintenanceSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "first"), IsNil)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "second"), IsNil)
+}
+
+func (s *MaintenanceSuite) TestRepackAndPrune(c *C) {
+	dangling, err := s.repo.inputGitCommand([]byte("dangling\n"), "hash-object", "-w", "--stdin")
+	c.Assert(err, IsNil)
+
+	result, err := s.repo.Maintenance(operations.MaintenanceOptions{Fsck: true})
+	c.Assert(err, IsNil)
+	c.Assert(result.IsHealthy(), Equals, true)
+	c.Assert(result.Findings, HasLen, 1)
+	c.Assert(result.Findings[0].Kind, Equals, operations.FsckDangling)
+	c.Assert(result.Findings[0].ObjectType, Equals, "blob")
+	c.Assert(result.Findings[0].Object, Equals, dangling[0])
+	c.Assert(result.Before.LooseObjects, Equals, 4)
+
+	result, err = s.repo.Maintenance(operations.MaintenanceOptions{
+		Repack:      true,
+		Prune:       true,
+		PruneExpire: time.Now().Add(time.Hour),
+		CommitGraph: true,
+		Fsck:        true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Findings, HasLen, 0)
+	c.Assert(result.After.Packs, Equals, 1)
+	c.Assert(result.After.PackedObjects, Equals, 3)
+	c.Assert(result.After.LooseObjects, Equals, 0)
+	c.Assert(result.After.Objects(), Equals, result.Before.Objects()-1)
+
+	_, err = os.Stat(filepath.Join(s.repo.path, ".git", "objects", "info", "commit-graph"))
+	c.Assert(err, IsNil)
+}
+
+func (s *MaintenanceSuite) TestMissingObjects(c *C) {
+	head, err := s.repo.getRef("HEAD~1")
+	c.Assert(err, IsNil)
+	c.Assert(os.Remove(filepath.Join(s.repo.path, ".git", "objects", head[:2], head[2:])), IsNil)
+
+	result, err := s.repo.Maintenance(operations.MaintenanceOptions{Fsck: true})
+	c.Assert(err, IsNil)
+	c.Assert(result.IsHealthy(), Equals, false)
+
+	var missing []string
+	for _, finding := range result.Findings {
+		if finding.Kind == operations.FsckMissing {
+			missing = append(missing, finding.String())
+		}
+	}
+	c.Assert(missing, DeepEquals, []string{"missing commit " + head})
+}
+
+func (s *ParserSuite) TestParseFsckOutput(c *C) {
+	findings := parseFsckOutput([]string{
+		"error: 4b825dc642cb6eb9a060e54bf8d69288fbee4904: object corrupt or missing: .git/objects/4b/825d",
+		"broken link from  commit f15b984d79229978595a59f04f40c46403c475b2",
+		"              to  commit c673a4c0b045577ac636e78391b3427ff5e7f337",
+		"dangling blob 45b983be36b73c0788dc9cbcb76cbb80fc7bb057",
+		"error: HEAD: invalid reflog entry c673a4c0b045577ac636e78391b3427ff5e7f337",
+	})
+
+	c.Assert(findings, HasLen, 3)
+	c.Assert(findings[0].Kind, Equals, operations.FsckCorrupt)
+	c.Assert(findings[0].Object, Equals, "4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+	c.Assert(findings[1].Kind, Equals, operations.FsckDangling)
+	c.Assert(findings[2].Kind, Equals, operations.FsckError)
+	c.Assert(findings[2].String(), Equals, "error: HEAD: invalid reflog entry c673a4c0b045577ac636e78391b3427ff5e7f337")
+}
@@ -0,0 +1,92 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) AddNote(ref, rev, message string, force bool) error {
+	args := []string{"notes", "--ref", operations.ExpandNotesRef(ref), "add", "--message", message}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, rev)
+
+	output, err := self.runGitCommand(args...)
+	if err != nil {
+		return fmt.Errorf("could not add note to %s: %s", rev, strings.Join(output, "\n"))
+	}
+
+	return nil
+}
+
+// lookupNote returns the id of the note blob for a revision.
+func (self *repository) lookupNote(ref, rev string) (string, error) {
+	if _, err := self.getRef(rev); err != nil {
+		return "", fmt.Errorf("could not resolve '%s'", rev)
+	}
+
+	output, err := self.runGitCommand("notes", "--ref", operations.ExpandNotesRef(ref), "list", rev)
+	if err != nil {
+		return "", operations.ErrNoteNotFound
+	}
+
+	return output[0], nil
+}
+
+func (self *repository) ReadNote(ref, rev string) (string, error) {
+	note, err := self.lookupNote(ref, rev)
+	if err != nil {
+		return "", err
+	}
+
+	return self.readNoteBlob(note)
+}
+
+func (self *repository) readNoteBlob(note string) (string, error) {
+	output, err := self.outputGitCommand("cat-file", "blob", note)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
+
+func (self *repository) RemoveNote(ref, rev string) error {
+	if _, err := self.lookupNote(ref, rev); err != nil {
+		return err
+	}
+
+	output, err := self.runGitCommand("notes", "--ref", operations.ExpandNotesRef(ref), "remove", rev)
+	if err != nil {
+		return fmt.Errorf("could not remove note from %s: %s", rev, strings.Join(output, "\n"))
+	}
+
+	return nil
+}
+
+func (self *repository) ListNotes(ref string) ([]*operations.Note, error) {
+	output, err := self.runGitCommand("notes", "--ref", operations.ExpandNotesRef(ref), "list")
+	if err != nil {
+		return nil, fmt.Errorf("could not list notes: %s", strings.Join(output, "\n"))
+	}
+
+	var notes []*operations.Note
+	for _, line := range output {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		message, err := self.readNoteBlob(fields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, &operations.Note{Object: fields[1], Note: fields[0], Message: message})
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,63 @@
+package gitwrap
+
+import (
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type NotesSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&NotesSuite{})
+
+func (s *NotesSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "first"), IsNil)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "second"), IsNil)
+}
+
+func (s *NotesSuite) TestNotes(c *C) {
+	c.Assert(operations.ExpandNotesRef(""), Equals, "refs/notes/commits")
+	c.Assert(operations.ExpandNotesRef("ci"), Equals, "refs/notes/ci")
+	c.Assert(operations.ExpandNotesRef("notes/ci"), Equals, "refs/notes/ci")
+
+	notes, err := s.repo.ListNotes("ci")
+	c.Assert(err, IsNil)
+	c.Assert(notes, HasLen, 0)
+
+	_, err = s.repo.ReadNote("ci", "HEAD")
+	c.Assert(err, Equals, operations.ErrNoteNotFound)
+
+	c.Assert(s.repo.AddNote("ci", "HEAD", "build: passed", false), IsNil)
+	c.Assert(s.repo.AddNote("ci", "HEAD", "build: failed", false), NotNil)
+	c.Assert(s.repo.AddNote("ci", "HEAD", "build: failed", true), IsNil)
+	c.Assert(s.repo.AddNote("ci", "HEAD~1", "build: passed", false), IsNil)
+	c.Assert(s.repo.AddNote("", "HEAD", "reviewed", false), IsNil)
+
+	message, err := s.repo.ReadNote("refs/notes/ci", "HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(message, Equals, "build: failed")
+
+	head, err := s.repo.getRef("HEAD")
+	c.Assert(err, IsNil)
+
+	notes, err = s.repo.ListNotes("ci")
+	c.Assert(err, IsNil)
+	c.Assert(notes, HasLen, 2)
+	for _, note := range notes {
+		if note.Object == head {
+			c.Assert(note.Message, Equals, "build: failed")
+		} else {
+			c.Assert(note.Message, Equals, "build: passed")
+		}
+	}
+
+	c.Assert(s.repo.RemoveNote("ci", "HEAD"), IsNil)
+	c.Assert(s.repo.RemoveNote("ci", "HEAD"), Equals, operations.ErrNoteNotFound)
+	c.Assert(s.repo.RemoveNote("ci", "missing"), NotNil)
+
+	message, err = s.repo.ReadNote("", "HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(message, Equals, "reviewed")
+}
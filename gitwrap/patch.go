@@ -0,0 +1,131 @@
+package gitwrap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) FormatPatch(commits string) ([]*operations.Patch, error) {
+	output, err := self.outputGitCommand("format-patch", "--stdout", "--no-signature", commits, "--")
+	if err != nil {
+		return nil, fmt.Errorf("could not format patches for %s: %s", commits, commandError(err))
+	}
+
+	return parseMailbox(output)
+}
+
+// mailboxSeparator matches the line that begins each message that
+// git format-patch writes.
+var mailboxSeparator = regexp.MustCompile(`(?m)^From ([0-9a-f]{40,64}) Mon Sep 17 00:00:00 2001\n`)
+
+// parseMailbox splits the output of "git format-patch --stdout" into
+// patches.
+func parseMailbox(data []byte) ([]*operations.Patch, error) {
+	var patches []*operations.Patch
+
+	starts := mailboxSeparator.FindAllSubmatchIndex(data, -1)
+	for idx, start := range starts {
+		end := len(data)
+		if idx+1 < len(starts) {
+			end = starts[idx+1][0]
+		}
+
+		contents := data[start[0]:end]
+		message, err := mail.ReadMessage(bufio.NewReader(bytes.NewReader(contents[start[1]-start[0]:])))
+		if err != nil {
+			return nil, fmt.Errorf("malformed patch: %s", err)
+		}
+
+		decoder := &mime.WordDecoder{}
+		subject, err := decoder.DecodeHeader(message.Header.Get("Subject"))
+		if err != nil {
+			subject = message.Header.Get("Subject")
+		}
+		author, err := decoder.DecodeHeader(message.Header.Get("From"))
+		if err != nil {
+			author = message.Header.Get("From")
+		}
+		date, err := message.Header.Date()
+		if err != nil {
+			return nil, fmt.Errorf("malformed patch date: %s", err)
+		}
+
+		patches = append(patches, &operations.Patch{
+			Commit:   string(data[start[2]:start[3]]),
+			Author:   author,
+			Date:     date,
+			Subject:  operations.TrimPatchSubject(subject),
+			Contents: contents,
+		})
+	}
+
+	return patches, nil
+}
+
+func (self *repository) ApplyPatch(patch io.Reader, toIndex, toWorktree, check bool) error {
+	args := []string{"apply"}
+	switch {
+	case toIndex && toWorktree:
+		args = append(args, "--index")
+	case toIndex:
+		args = append(args, "--cached")
+	case !toWorktree && !check:
+		return fmt.Errorf("a patch must apply to the index, the working tree, or both")
+	}
+	if check {
+		args = append(args, "--check")
+	}
+	args = append(args, "-")
+
+	cmd := self.gitCommand(args...)
+	cmd.Stdin = patch
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("patch does not apply: %s", strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// ApplyMailbox commits each patch in the mailbox, preserving the
+// author and message. If a patch does not apply, no commits are
+// made and the repository is returned to its prior state; "git am
+// --abort" restores the original HEAD while keeping uncommitted
+// changes.
+func (self *repository) ApplyMailbox(mailbox io.Reader) error {
+	if self.bare || !self.exists {
+		return fmt.Errorf("cannot modify the working tree of this repository")
+	}
+
+	if _, err := self.getRef("HEAD"); err != nil {
+		return fmt.Errorf("cannot apply patches without a commit to apply them to")
+	}
+
+	cmd := self.gitCommand("am", "--quiet")
+	cmd.Stdin = mailbox
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		catcher := []string{strings.TrimSpace(string(output))}
+		if abortErr := self.checkGitCommand("am", "--abort"); abortErr != nil {
+			self.state = states.UnresolvedOperation
+			catcher = append(catcher, "could not abort: "+abortErr.Error())
+		} else {
+			self.state = states.FailedOperation
+		}
+
+		return fmt.Errorf("could not apply patches: %s", strings.Join(catcher, "; "))
+	}
+
+	return nil
+}
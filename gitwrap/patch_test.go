@@ -0,0 +1,120 @@
+package gitwrap
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/states"
+	. "gopkg.in/check.v1"
+)
+
+type PatchSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&PatchSuite{})
+
+func (s *PatchSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "root"), IsNil)
+}
+
+func (s *PatchSuite) commitFile(c *C, name, contents, author, message string) {
+	c.Assert(ioutil.WriteFile(filepath.Join(s.repo.path, name), []byte(contents), 0644), IsNil)
+	c.Assert(s.repo.Stage(name), IsNil)
+	c.Assert(s.repo.checkGitCommand("commit", "--author", author, "-m", message), IsNil)
+}
+
+func (s *PatchSuite) TestFormatAndApplyMailbox(c *C) {
+	base, err := s.repo.getRef("HEAD")
+	c.Assert(err, IsNil)
+
+	s.commitFile(c, "a.txt", "one\n", "Zoë Example <zoe@example.com>", "add a\n\nwith a body")
+	s.commitFile(c, "b.txt", "two\n", "Gitgone <gitgone@example.com>", "add b")
+
+	patches, err := s.repo.FormatPatch(base)
+	c.Assert(err, IsNil)
+	c.Assert(patches, HasLen, 2)
+	c.Assert(patches[0].Subject, Equals, "add a")
+	c.Assert(patches[0].Author, Equals, "Zoë Example <zoe@example.com>")
+	c.Assert(patches[1].Subject, Equals, "add b")
+	c.Assert(bytes.Contains(patches[1].Contents, []byte("+two")), Equals, true)
+
+	c.Assert(s.repo.Reset(base, true), IsNil)
+
+	mailbox := &bytes.Buffer{}
+	for _, patch := range patches {
+		mailbox.Write(patch.Contents)
+	}
+	c.Assert(s.repo.ApplyMailbox(mailbox), IsNil)
+
+	authors, err := s.repo.runGitCommand("log", "--format=%an|%s", base+"..HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(authors, DeepEquals, []string{"Gitgone|add b", "Zoë Example|add a"})
+}
+
+func (s *PatchSuite) TestApplyPatch(c *C) {
+	s.commitFile(c, "a.txt", "one\n", "Gitgone <gitgone@example.com>", "add a")
+	s.commitFile(c, "a.txt", "two\n", "Gitgone <gitgone@example.com>", "change a")
+
+	patches, err := s.repo.FormatPatch("HEAD~1")
+	c.Assert(err, IsNil)
+	c.Assert(patches, HasLen, 1)
+	patch := patches[0].Contents
+
+	// already applied
+	c.Assert(s.repo.ApplyPatch(bytes.NewReader(patch), true, true, true), NotNil)
+
+	c.Assert(s.repo.Reset("HEAD~1", true), IsNil)
+	c.Assert(s.repo.ApplyPatch(bytes.NewReader(patch), true, true, true), IsNil)
+	c.Assert(s.repo.ApplyPatch(bytes.NewReader(patch), true, false, false), IsNil)
+
+	staged, err := s.repo.runGitCommand("diff", "--cached", "--name-only")
+	c.Assert(err, IsNil)
+	c.Assert(staged, DeepEquals, []string{"a.txt"})
+
+	contents, err := ioutil.ReadFile(filepath.Join(s.repo.path, "a.txt"))
+	c.Assert(err, IsNil)
+	c.Assert(string(contents), Equals, "one\n")
+
+	c.Assert(s.repo.ApplyMailbox(bytes.NewReader(patch)), NotNil)
+	c.Assert(s.repo.state, Equals, states.FailedOperation)
+}
+
+func (s *PatchSuite) TestApplyMailboxKeepsLocalChanges(c *C) {
+	s.commitFile(c, "a.txt", "one\n", "Gitgone <gitgone@example.com>", "add a")
+	s.commitFile(c, "local.txt", "committed\n", "Gitgone <gitgone@example.com>", "add local")
+	base, err := s.repo.getRef("HEAD")
+	c.Assert(err, IsNil)
+
+	s.commitFile(c, "c.txt", "c\n", "Gitgone <gitgone@example.com>", "add c")
+	s.commitFile(c, "a.txt", "two\n", "Gitgone <gitgone@example.com>", "change a")
+	patches, err := s.repo.FormatPatch(base)
+	c.Assert(err, IsNil)
+	c.Assert(patches, HasLen, 2)
+
+	// the second patch conflicts, after the first is committed.
+	c.Assert(s.repo.Reset(base, true), IsNil)
+	s.commitFile(c, "a.txt", "conflict\n", "Gitgone <gitgone@example.com>", "conflict a")
+	head, err := s.repo.getRef("HEAD")
+	c.Assert(err, IsNil)
+
+	local := filepath.Join(s.repo.path, "local.txt")
+	c.Assert(ioutil.WriteFile(local, []byte("uncommitted\n"), 0644), IsNil)
+
+	mailbox := append(append([]byte{}, patches[0].Contents...), patches[1].Contents...)
+	c.Assert(s.repo.ApplyMailbox(bytes.NewReader(mailbox)), NotNil)
+	c.Assert(s.repo.state, Equals, states.FailedOperation)
+
+	after, err := s.repo.getRef("HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(after, Equals, head)
+
+	contents, err := ioutil.ReadFile(local)
+	c.Assert(err, IsNil)
+	c.Assert(string(contents), Equals, "uncommitted\n")
+
+	_, err = ioutil.ReadFile(filepath.Join(s.repo.path, "c.txt"))
+	c.Assert(err, NotNil)
+}
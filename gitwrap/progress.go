@@ -0,0 +1,123 @@
+package gitwrap
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) SetProgress(progress operations.ProgressFunc) {
+	self.progress = progress
+}
+
+// progressCommand returns a git command that, when the repository
+// has a progress function, reports progress on standard error even
+// though it is not attached to a terminal.
+func (self *repository) progressCommand(args ...string) *exec.Cmd {
+	if self.progress != nil && len(args) > 0 {
+		args = append([]string{args[0], "--progress"}, args[1:]...)
+	}
+
+	return self.gitCommand(args...)
+}
+
+// runProgressCommand runs a command created by progressCommand,
+// passing progress to the repository's progress function, and
+// returns the output in the same form as runGitCommand.
+func (self *repository) runProgressCommand(cmd *exec.Cmd) ([]string, error) {
+	output := &bytes.Buffer{}
+
+	err := self.streamProgressCommand(cmd, output, output)
+
+	return strings.Split(strings.Trim(output.String(), " \t\n\r"), "\n"), err
+}
+
+// streamProgressCommand runs a command created by progressCommand,
+// writing its standard output and standard error separately, for
+// commands with machine readable output.
+func (self *repository) streamProgressCommand(cmd *exec.Cmd, stdout, stderr io.Writer) error {
+	cmd.Stdout = stdout
+	if self.progress == nil {
+		cmd.Stderr = stderr
+	} else {
+		cmd.Stderr = io.MultiWriter(stderr, &progressWriter{report: self.progress})
+	}
+
+	return cmd.Run()
+}
+
+// progressWriter parses git's human readable progress output, in
+// which updates to the same line are separated by carriage returns.
+type progressWriter struct {
+	report operations.ProgressFunc
+	line   []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b == '\r' || b == '\n' {
+			if update, ok := parseProgress(string(w.line)); ok {
+				w.report(update)
+			}
+			w.line = w.line[:0]
+			continue
+		}
+		w.line = append(w.line, b)
+	}
+
+	return len(p), nil
+}
+
+var progressPattern = regexp.MustCompile(`^(remote: )?([A-Za-z ]+):\s+\d+% \((\d+)/(\d+)\)(?:, ([\d.]+) (bytes|KiB|MiB|GiB))?`)
+
+var progressUnits = map[string]float64{
+	"bytes": 1,
+	"KiB":   1 << 10,
+	"MiB":   1 << 20,
+	"GiB":   1 << 30,
+}
+
+// parseProgress converts a line of progress output, such as
+// "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s", into a
+// progress update.
+func parseProgress(line string) (operations.Progress, bool) {
+	match := progressPattern.FindStringSubmatch(line)
+	if match == nil {
+		return operations.Progress{}, false
+	}
+
+	update := operations.Progress{}
+
+	switch {
+	case match[1] != "":
+		update.Stage = operations.ProgressRemote
+	case match[2] == "Receiving objects":
+		update.Stage = operations.ProgressReceiving
+	case match[2] == "Resolving deltas":
+		update.Stage = operations.ProgressIndexing
+	case match[2] == "Writing objects":
+		update.Stage = operations.ProgressSending
+	case match[2] == "Updating files" || match[2] == "Checking out files":
+		update.Stage = operations.ProgressCheckout
+	default:
+		// local counting, compressing and enumerating.
+		update.Stage = operations.ProgressRemote
+	}
+
+	update.Current, _ = strconv.ParseUint(match[3], 10, 64)
+	update.Total, _ = strconv.ParseUint(match[4], 10, 64)
+
+	if match[5] != "" {
+		size, err := strconv.ParseFloat(match[5], 64)
+		if err == nil {
+			update.Bytes = uint64(size * progressUnits[match[6]])
+		}
+	}
+
+	return update, true
+}
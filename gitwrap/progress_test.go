@@ -0,0 +1,45 @@
+package gitwrap
+
+import (
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+func (s *ParserSuite) TestProgressParsing(c *C) {
+	update, ok := parseProgress("Receiving objects:  45% (450/1000), 1.50 MiB | 2.00 MiB/s")
+	c.Assert(ok, Equals, true)
+	c.Assert(update.Stage, Equals, operations.ProgressReceiving)
+	c.Assert(update.Current, Equals, uint64(450))
+	c.Assert(update.Total, Equals, uint64(1000))
+	c.Assert(update.Bytes, Equals, uint64(1.5*(1<<20)))
+
+	update, ok = parseProgress("remote: Counting objects: 100% (10/10), done.")
+	c.Assert(ok, Equals, true)
+	c.Assert(update.Stage, Equals, operations.ProgressRemote)
+
+	update, ok = parseProgress("Resolving deltas:  50% (1/2)")
+	c.Assert(ok, Equals, true)
+	c.Assert(update.Stage, Equals, operations.ProgressIndexing)
+
+	update, ok = parseProgress("Updating files: 100% (3/3), done.")
+	c.Assert(ok, Equals, true)
+	c.Assert(update.Stage, Equals, operations.ProgressCheckout)
+	c.Assert(update.Current, Equals, uint64(3))
+
+	_, ok = parseProgress("Cloning into 'repo'...")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *ParserSuite) TestProgressWriterSplitsUpdates(c *C) {
+	var updates []operations.Progress
+	w := &progressWriter{report: func(p operations.Progress) { updates = append(updates, p) }}
+
+	_, err := w.Write([]byte("Receiving objects:  50% (1/2)\rReceiving obj"))
+	c.Assert(err, IsNil)
+	_, err = w.Write([]byte("ects: 100% (2/2), done.\nResolving deltas: 100% (1/1), done.\n"))
+	c.Assert(err, IsNil)
+
+	c.Assert(updates, HasLen, 3)
+	c.Assert(updates[1].Current, Equals, uint64(2))
+	c.Assert(updates[2].Stage, Equals, operations.ProgressIndexing)
+}
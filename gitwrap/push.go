@@ -0,0 +1,140 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) Push(remote, branch string) error {
+	_, err := self.PushWithOptions(operations.PushOptions{
+		Remote:   remote,
+		Refspecs: []string{branch},
+	})
+
+	return err
+}
+
+func (self *repository) PushWithOptions(opts operations.PushOptions) (*operations.PushResult, error) {
+	args := []string{"push", "--porcelain"}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	for _, lease := range opts.Leases {
+		args = append(args, fmt.Sprintf("--force-with-lease=%s:%s", lease.Ref, lease.Expected))
+	}
+	if opts.Delete {
+		args = append(args, "--delete")
+	}
+	if opts.Tags {
+		args = append(args, "--tags")
+	}
+	if opts.Atomic {
+		args = append(args, "--atomic")
+	}
+	args = append(args, opts.Remote)
+	args = append(args, opts.Refspecs...)
+
+	output, stderr, err := self.outputNetworkGitCommand(opts.Remote, args...)
+
+	result := self.parsePushOutput(string(output))
+	if len(result.Updates) == 0 && err != nil {
+		// git failed before it attempted to update any refs.
+		self.state = states.FailedOperation
+		return result, fmt.Errorf("problem pushing to %s: %s", opts.Remote, stderr)
+	}
+
+	if err = result.Error(); err != nil {
+		self.state = states.PartialOperation
+	}
+
+	return result, err
+}
+
+// parsePushOutput parses the output of "git push --porcelain", which
+// reports each ref on a line of the form "<flag>\t<src>:<dst>\t<summary>
+// (<reason>)" between a "To <url>" line and a "Done" line.
+func (self *repository) parsePushOutput(output string) *operations.PushResult {
+	result := &operations.PushResult{}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || len(fields[0]) != 1 {
+			continue
+		}
+
+		refs := strings.SplitN(fields[1], ":", 2)
+		if len(refs) != 2 {
+			continue
+		}
+
+		update := &operations.PushUpdate{
+			Source:      refs[0],
+			Destination: refs[1],
+		}
+
+		summary, reason := fields[2], ""
+		if idx := strings.Index(summary, " ("); idx >= 0 && strings.HasSuffix(summary, ")") {
+			reason = summary[idx+2 : len(summary)-1]
+			summary = summary[:idx]
+		}
+
+		switch fields[0] {
+		case " ":
+			update.Status = operations.PushUpdated
+			update.Old, update.New = self.expandRange(summary, "..")
+		case "+":
+			update.Status = operations.PushForced
+			update.Old, update.New = self.expandRange(summary, "...")
+		case "*":
+			update.Status = operations.PushCreated
+			update.New = self.expandSha(update.Source)
+		case "-":
+			update.Status = operations.PushDeleted
+		case "=":
+			update.Status = operations.PushUpToDate
+			update.New = self.expandSha(update.Source)
+			update.Old = update.New
+		case "!":
+			update.Status = operations.PushRejected
+			update.Reason = reason
+			if update.Reason == "" {
+				update.Reason = strings.Trim(summary, "[]")
+			}
+		default:
+			continue
+		}
+
+		result.Updates = append(result.Updates, update)
+	}
+
+	return result
+}
+
+// expandRange splits an abbreviated "old..new" range from push
+// output into full object names.
+func (self *repository) expandRange(summary, sep string) (string, string) {
+	parts := strings.SplitN(summary, sep, 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+
+	return self.expandSha(parts[0]), self.expandSha(parts[1])
+}
+
+// expandSha resolves a ref or abbreviated object name to a full
+// object name, returning the input if it cannot be resolved locally.
+func (self *repository) expandSha(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	output, err := self.runGitCommand("rev-parse", "--verify", "--quiet", name+"^{object}")
+	if err != nil {
+		return name
+	}
+
+	return output[0]
+}
@@ -0,0 +1,76 @@
+package gitwrap
+
+import (
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+func (s *ParserSuite) TestPushOutputParsing(c *C) {
+	// outside of a repository abbreviated names are not expanded.
+	repo := &repository{path: c.MkDir()}
+
+	result := repo.parsePushOutput("To /srv/git/upstream.git\n" +
+		" \trefs/heads/master:refs/heads/master\t1a2b3c4..5d6e7f8\n" +
+		"+\trefs/heads/topic:refs/heads/topic\t1a2b3c4...5d6e7f8 (forced update)\n" +
+		"*\trefs/tags/v1.0:refs/tags/v1.0\t[new tag]\n" +
+		"-\t:refs/heads/old\t[deleted]\n" +
+		"=\trefs/heads/stable:refs/heads/stable\t[up to date]\n" +
+		"!\trefs/heads/next:refs/heads/next\t[rejected] (non-fast-forward)\n" +
+		"!\trefs/heads/hook:refs/heads/hook\t[remote rejected] (pre-receive hook declined)\n" +
+		"Done\n")
+
+	c.Assert(result.Updates, HasLen, 7)
+
+	c.Assert(result.Updates[0].Status, Equals, operations.PushUpdated)
+	c.Assert(result.Updates[0].Old, Equals, "1a2b3c4")
+	c.Assert(result.Updates[0].New, Equals, "5d6e7f8")
+
+	c.Assert(result.Updates[1].Status, Equals, operations.PushForced)
+	c.Assert(result.Updates[1].Reason, Equals, "")
+	c.Assert(result.Updates[1].New, Equals, "5d6e7f8")
+
+	c.Assert(result.Updates[2].Status, Equals, operations.PushCreated)
+	c.Assert(result.Updates[3].Status, Equals, operations.PushDeleted)
+	c.Assert(result.Updates[3].Source, Equals, "")
+	c.Assert(result.Updates[3].Destination, Equals, "refs/heads/old")
+	c.Assert(result.Updates[4].Status, Equals, operations.PushUpToDate)
+
+	c.Assert(result.Rejected(), HasLen, 2)
+	c.Assert(result.Updates[5].Reason, Equals, "non-fast-forward")
+	c.Assert(result.Updates[6].Reason, Equals, "pre-receive hook declined")
+	c.Assert(result.Error(), NotNil)
+}
+
+type PushSuite struct{}
+
+var _ = Suite(&PushSuite{})
+
+func (s *PushSuite) TestPushHead(c *C) {
+	upstream := NewRepository(c.MkDir())
+	c.Assert(upstream.Init(true, "master", ""), IsNil)
+
+	repo := newTestRepository(c)
+	c.Assert(repo.checkGitCommand("commit", "--allow-empty", "-m", "first"), IsNil)
+	c.Assert(repo.checkGitCommand("remote", "add", "origin", upstream.path), IsNil)
+
+	result, err := repo.PushWithOptions(operations.PushOptions{Remote: "origin", Refspecs: []string{"HEAD"}})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates, HasLen, 1)
+	c.Assert(result.Updates[0].Destination, Equals, "refs/heads/master")
+	c.Assert(result.Updates[0].Status, Equals, operations.PushCreated)
+
+	head, err := repo.getRef("HEAD")
+	c.Assert(err, IsNil)
+	pushed, err := upstream.getRef("refs/heads/master")
+	c.Assert(err, IsNil)
+	c.Assert(pushed, Equals, head)
+
+	c.Logf("a detached HEAD cannot be pushed without a destination")
+	c.Assert(repo.checkGitCommand("checkout", "--quiet", "--detach"), IsNil)
+	_, err = repo.PushWithOptions(operations.PushOptions{Remote: "origin", Refspecs: []string{"HEAD"}})
+	c.Assert(err, NotNil)
+
+	result, err = repo.PushWithOptions(operations.PushOptions{Remote: "origin", Refspecs: []string{"HEAD:refs/heads/detached"}})
+	c.Assert(err, IsNil)
+	c.Assert(result.Updates[0].Destination, Equals, "refs/heads/detached")
+}
@@ -0,0 +1,167 @@
+package gitwrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// Reflog returns the entries in the reflog of a ref, most recent
+// first, so that the entry at index n describes ref@{n}.
+func (self *repository) Reflog(ref string) ([]*operations.ReflogEntry, error) {
+	name, err := self.fullRefName(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := self.runGitCommand("rev-parse", "--git-path", "logs/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("could not find reflog for %s: %s", ref, strings.Join(path, "\n"))
+	}
+
+	fn := path[0]
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(self.path, fn)
+	}
+
+	data, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return parseReflog(string(data))
+}
+
+// fullRefName expands a ref to its full name, leaving HEAD as is.
+func (self *repository) fullRefName(ref string) (string, error) {
+	if ref == "" || ref == "HEAD" {
+		return "HEAD", nil
+	}
+
+	output, err := self.runGitCommand("rev-parse", "--symbolic-full-name", ref)
+	if err != nil || output[0] == "" {
+		return "", fmt.Errorf("%s is not a ref", ref)
+	}
+
+	return output[0], nil
+}
+
+// parseReflog parses the contents of a reflog file, which has an
+// entry per line in the form "<old> <new> <name> <<email>> <time>
+// <zone>\t<message>", oldest first.
+func parseReflog(data string) ([]*operations.ReflogEntry, error) {
+	var entries []*operations.ReflogEntry
+
+	for _, line := range strings.Split(data, "\n") {
+		if line == "" {
+			continue
+		}
+
+		message := ""
+		if idx := strings.Index(line, "\t"); idx >= 0 {
+			line, message = line[:idx], line[idx+1:]
+		}
+
+		parts := strings.SplitN(line, " ", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed reflog entry '%s'", line)
+		}
+
+		ident, err := parseReflogIdent(parts[2])
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append([]*operations.ReflogEntry{{
+			Old:       parts[0],
+			New:       parts[1],
+			Committer: ident,
+			Message:   message,
+		}}, entries...)
+	}
+
+	return entries, nil
+}
+
+func parseReflogIdent(ident string) (operations.Identity, error) {
+	result := operations.Identity{}
+
+	start := strings.Index(ident, "<")
+	end := strings.LastIndex(ident, ">")
+	if start < 0 || end < start {
+		return result, fmt.Errorf("malformed reflog identity '%s'", ident)
+	}
+	result.Name = strings.TrimSpace(ident[:start])
+	result.Email = ident[start+1 : end]
+
+	fields := strings.Fields(ident[end+1:])
+	if len(fields) != 2 {
+		return result, fmt.Errorf("malformed reflog time '%s'", ident)
+	}
+
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return result, fmt.Errorf("malformed reflog time '%s'", ident)
+	}
+
+	result.When = time.Unix(seconds, 0).In(parseTimeZone(fields[1]))
+
+	return result, nil
+}
+
+// ReflogExpire removes the entries older than the given time from
+// the reflog of a ref, or from every reflog when ref is empty.
+func (self *repository) ReflogExpire(ref string, before time.Time) error {
+	expire := before.Format(time.RFC3339)
+	args := []string{"reflog", "expire", "--expire=" + expire, "--expire-unreachable=" + expire}
+
+	if ref == "" {
+		args = append(args, "--all")
+	} else {
+		name, err := self.fullRefName(ref)
+		if err != nil {
+			return err
+		}
+		args = append(args, name)
+	}
+
+	output, err := self.runGitCommand(args...)
+	if err != nil {
+		return fmt.Errorf("could not expire reflog: %s", strings.Join(output, "\n"))
+	}
+
+	return nil
+}
+
+// UpdateRef points a ref at a new object, failing if the ref does
+// not currently point at expected, unless expected is empty. Refs
+// other than HEAD that are not full names must already exist.
+func (self *repository) UpdateRef(ref, target, expected, message string) error {
+	if ref != "HEAD" && !strings.HasPrefix(ref, "refs/") {
+		name, err := self.fullRefName(ref)
+		if err != nil {
+			return err
+		}
+		ref = name
+	}
+
+	args := []string{"update-ref", "-m", message, ref, target}
+	if expected != "" {
+		args = append(args, expected)
+	}
+
+	output, err := self.runGitCommand(args...)
+	if err != nil {
+		return fmt.Errorf("could not update %s: %s", ref, strings.Join(output, "\n"))
+	}
+
+	return nil
+}
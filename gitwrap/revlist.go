@@ -0,0 +1,48 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+func (self *repository) ResolveCommit(rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	commit, err := self.getRef(rev + "^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("could not resolve '%s' to a commit", rev)
+	}
+
+	return commit, nil
+}
+
+func (self *repository) RevList(include, exclude []string) ([]*operations.CommitNode, error) {
+	args := []string{"rev-list", "--topo-order", "--parents"}
+	args = append(args, include...)
+	if len(exclude) > 0 {
+		args = append(args, "--not")
+		args = append(args, exclude...)
+	}
+	args = append(args, "--")
+
+	output, err := self.outputGitCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list commits: %s", commandError(err))
+	}
+
+	var commits []*operations.CommitNode
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		commits = append(commits, &operations.CommitNode{ID: fields[0], Parents: fields[1:]})
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,149 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tychoish/gitgone/hooks"
+	"github.com/tychoish/gitgone/signing"
+	"github.com/tychoish/gitgone/states"
+)
+
+func (self *repository) SetSigner(signer signing.Signer) {
+	self.signer = signer
+}
+
+func (self *repository) SetVerifier(verifier signing.Verifier) {
+	self.verifier = verifier
+}
+
+// getVerifier returns the configured verifier, or one that uses the
+// local GnuPG keyring and the repository's allowed SSH signers.
+func (self *repository) getVerifier() signing.Verifier {
+	if self.verifier != nil {
+		return self.verifier
+	}
+
+	allowedSigners, _ := self.Config().Get("gpg.ssh.allowedSignersFile")
+	return signing.NewVerifier(allowedSigners)
+}
+
+// getIdent returns the author or committer identity, as named by the
+// GIT_AUTHOR_IDENT or GIT_COMMITTER_IDENT variables, formatted for use
+// in git objects.
+func (self *repository) getIdent(variable string) (string, error) {
+	output, err := self.runGitCommand("var", variable)
+	if err != nil {
+		return "", fmt.Errorf("could not determine identity: %s", strings.Join(output, "\n"))
+	}
+
+	return output[0], nil
+}
+
+// createSignedCommit writes a commit of the index with a signature
+// from the repository's signer, and moves the current branch to it.
+// When amending, the new commit replaces HEAD and keeps its author.
+func (self *repository) createSignedCommit(message string, amend bool) error {
+	// git commit runs hooks itself, but signed commits are written
+	// with plumbing commands, which do not.
+	if err := self.runHook(hooks.PreCommit, nil); err != nil {
+		return err
+	}
+
+	output, err := self.runGitCommand("write-tree")
+	if err != nil {
+		self.state = states.FailedOperation
+		return fmt.Errorf("could not write tree: %s", strings.Join(output, "\n"))
+	}
+
+	commit := &signing.Commit{Tree: output[0], Message: message}
+
+	head, err := self.getRef("HEAD")
+	if err != nil {
+		if amend {
+			return fmt.Errorf("there is no commit to amend")
+		}
+		head = ""
+	}
+
+	if amend {
+		output, err = self.runGitCommand("show", "--no-patch", "--date=raw",
+			"--format=%an <%ae> %ad%n%P", "HEAD")
+		if err != nil {
+			return fmt.Errorf("could not read HEAD: %s", strings.Join(output, "\n"))
+		}
+		commit.Author = output[0]
+		if len(output) > 1 {
+			commit.Parents = strings.Fields(output[1])
+		}
+	} else {
+		if head != "" {
+			commit.Parents = []string{head}
+
+			tree, _ := self.getRef("HEAD^{tree}")
+			if tree == commit.Tree {
+				return fmt.Errorf("nothing to commit")
+			}
+		}
+
+		commit.Author, err = self.getIdent("GIT_AUTHOR_IDENT")
+		if err != nil {
+			return err
+		}
+	}
+
+	commit.Committer, err = self.getIdent("GIT_COMMITTER_IDENT")
+	if err != nil {
+		return err
+	}
+
+	commit.Message, err = self.commitMessageHook(commit.Message)
+	if err != nil {
+		return err
+	}
+
+	data, err := signing.SignCommit(commit, self.signer)
+	if err != nil {
+		self.state = states.FailedOperation
+		return err
+	}
+
+	output, err = self.inputGitCommand(data, "hash-object", "-t", "commit", "-w", "--stdin")
+	if err != nil {
+		self.state = states.FailedOperation
+		return fmt.Errorf("could not write commit: %s", strings.Join(output, "\n"))
+	}
+
+	reason := "commit"
+	if amend {
+		reason = "commit (amend)"
+	}
+	subject := strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+
+	// the old value guards against concurrent updates of the branch
+	output, err = self.runGitCommand("update-ref", "-m", reason+": "+subject, "HEAD", output[0], head)
+	if err != nil {
+		self.state = states.FailedOperation
+		return fmt.Errorf("could not update HEAD: %s", strings.Join(output, "\n"))
+	}
+
+	// like git, ignore the result of post-commit hooks
+	_ = self.runHook(hooks.PostCommit, nil)
+
+	return nil
+}
+
+// VerifyCommit checks the signature of the commit that a revision
+// refers to.
+func (self *repository) VerifyCommit(rev string) (*signing.Verification, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	data, err := self.outputGitCommand("cat-file", "commit", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve '%s' to a commit", rev)
+	}
+
+	return signing.VerifyCommit(data, self.getVerifier())
+}
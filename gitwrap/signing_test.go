@@ -0,0 +1,69 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/signing"
+	. "gopkg.in/check.v1"
+)
+
+type SigningSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&SigningSuite{})
+
+func (s *SigningSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "first"), IsNil)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "second"), IsNil)
+}
+
+func (s *SigningSuite) TestSignedCommits(c *C) {
+	signer := signing.NewTestSigner("gitgone@example.com")
+	s.repo.SetSigner(signer)
+	s.repo.SetVerifier(signer)
+
+	result, err := s.repo.VerifyCommit("HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, signing.Unsigned)
+
+	c.Assert(s.repo.Commit("nothing"), NotNil)
+
+	c.Assert(ioutil.WriteFile(filepath.Join(s.repo.path, "README"), []byte("gitgone"), 0644), IsNil)
+	c.Assert(s.repo.Stage("README"), IsNil)
+	c.Assert(s.repo.Commit("third\n\nwith a body"), IsNil)
+
+	result, err = s.repo.VerifyCommit("")
+	c.Assert(err, IsNil)
+	c.Assert(result.IsGood(), Equals, true)
+	c.Assert(result.Identity, Equals, "gitgone@example.com")
+
+	output, err := s.repo.runGitCommand("log", "--format=%s", "--max-count=2")
+	c.Assert(err, IsNil)
+	c.Assert(output, DeepEquals, []string{"third", "second"})
+
+	c.Assert(ioutil.WriteFile(filepath.Join(s.repo.path, "README"), []byte("changed"), 0644), IsNil)
+	c.Assert(s.repo.AmendAll("amended"), IsNil)
+
+	output, err = s.repo.runGitCommand("log", "--format=%s %an", "--max-count=2")
+	c.Assert(err, IsNil)
+	c.Assert(output, DeepEquals, []string{"amended Gitgone", "second Gitgone"})
+	c.Assert(s.repo.checkGitCommand("diff", "--quiet", "HEAD"), IsNil)
+
+	result, err = s.repo.VerifyCommit("HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(result.IsGood(), Equals, true)
+
+	result, err = s.repo.VerifyCommit("HEAD~1")
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, signing.Unsigned)
+
+	s.repo.SetVerifier(signing.NewTestSigner("mallory@example.com"))
+	result, err = s.repo.VerifyCommit("HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, signing.UnknownKey)
+
+	c.Assert(s.repo.checkGitCommand("fsck", "--strict"), IsNil)
+}
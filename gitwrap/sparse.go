@@ -0,0 +1,63 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tychoish/gitgone/states"
+)
+
+// SparseCheckoutInit enables sparse checkout, limiting the working
+// tree to the files at the top level until patterns are set. In cone
+// mode, patterns are directories.
+func (self *repository) SparseCheckoutInit(cone bool) error {
+	if cone {
+		return self.sparseCheckout("init", "--cone")
+	}
+
+	return self.sparseCheckout("init", "--no-cone")
+}
+
+func (self *repository) SparseCheckoutSet(patterns ...string) error {
+	return self.sparseCheckout(append([]string{"set"}, patterns...)...)
+}
+
+func (self *repository) SparseCheckoutAdd(patterns ...string) error {
+	return self.sparseCheckout(append([]string{"add"}, patterns...)...)
+}
+
+func (self *repository) SparseCheckoutList() ([]string, error) {
+	output, err := self.outputGitCommand("sparse-checkout", "list")
+	if err != nil {
+		return nil, fmt.Errorf("could not list sparse checkout patterns: %s", commandError(err))
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+
+	return patterns, nil
+}
+
+// SparseCheckoutDisable restores the full working tree, leaving the
+// patterns in place for when sparse checkout is enabled again.
+func (self *repository) SparseCheckoutDisable() error {
+	return self.sparseCheckout("disable")
+}
+
+func (self *repository) sparseCheckout(args ...string) error {
+	if self.bare || !self.exists {
+		return fmt.Errorf("cannot modify the working tree of this repository")
+	}
+
+	output, err := self.runGitCommand(append([]string{"sparse-checkout"}, args...)...)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return fmt.Errorf("problem running sparse-checkout %s: %s", args[0], strings.Join(output, "\n"))
+	}
+
+	return nil
+}
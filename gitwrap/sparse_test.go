@@ -0,0 +1,120 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	. "gopkg.in/check.v1"
+)
+
+type SparseSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&SparseSuite{})
+
+func (s *SparseSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c)
+
+	s.writeFiles(c, "README", "a/x", "a/b/y", "c/z")
+	c.Assert(s.repo.Stage("."), IsNil)
+	c.Assert(s.repo.Commit("initial"), IsNil)
+}
+
+func (s *SparseSuite) writeFiles(c *C, names ...string) {
+	for _, name := range names {
+		fn := filepath.Join(s.repo.path, name)
+		c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fn, []byte(name+"\n"), 0644), IsNil)
+	}
+}
+
+func (s *SparseSuite) checkedOut(c *C) []string {
+	var files []string
+	err := filepath.Walk(s.repo.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(s.repo.path, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	return files
+}
+
+func (s *SparseSuite) TestConeMode(c *C) {
+	c.Assert(s.repo.SparseCheckoutInit(true), IsNil)
+	c.Assert(s.checkedOut(c), DeepEquals, []string{"README"})
+
+	patterns, err := s.repo.SparseCheckoutList()
+	c.Assert(err, IsNil)
+	c.Assert(patterns, HasLen, 0)
+
+	// a/x is in the parent of a/b, and so is included
+	c.Assert(s.repo.SparseCheckoutSet("a/b"), IsNil)
+	c.Assert(s.checkedOut(c), DeepEquals, []string{"README", "a/b/y", "a/x"})
+
+	c.Assert(s.repo.SparseCheckoutAdd("c"), IsNil)
+	patterns, err = s.repo.SparseCheckoutList()
+	c.Assert(err, IsNil)
+	c.Assert(patterns, DeepEquals, []string{"a/b", "c"})
+
+	contents, err := ioutil.ReadFile(filepath.Join(s.repo.path, ".git", "info", "sparse-checkout"))
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	c.Assert(lines, DeepEquals, operations.FormatConePatterns(patterns))
+	c.Assert(operations.ParseConePatterns(lines), DeepEquals, patterns)
+
+	matcher := operations.NewSparseMatcher(true, patterns)
+	for _, fn := range []string{"README", "a/b/y", "c/z"} {
+		c.Assert(matcher.Includes(fn), Equals, true, Commentf(fn))
+	}
+	c.Assert(matcher.Includes("a/x"), Equals, true)
+	c.Assert(matcher.Includes("d/w"), Equals, false)
+
+	c.Assert(s.checkedOut(c), DeepEquals, []string{"README", "a/b/y", "a/x", "c/z"})
+
+	c.Assert(s.repo.CreateBranch("feature", "HEAD"), IsNil)
+	c.Assert(s.repo.Checkout("feature"), IsNil)
+	s.writeFiles(c, "d/w", "c/new")
+	c.Assert(s.repo.checkGitCommand("add", "--sparse", "d/w", "c/new"), IsNil)
+	c.Assert(s.repo.Commit("more files"), IsNil)
+	c.Assert(s.repo.Checkout("master"), IsNil)
+	c.Assert(s.repo.Checkout("feature"), IsNil)
+	c.Assert(s.checkedOut(c), DeepEquals, []string{"README", "a/b/y", "a/x", "c/new", "c/z"})
+
+	c.Assert(s.repo.SparseCheckoutDisable(), IsNil)
+	c.Assert(s.checkedOut(c), DeepEquals, []string{"README", "a/b/y", "a/x", "c/new", "c/z", "d/w"})
+}
+
+func (s *SparseSuite) TestPatternMode(c *C) {
+	c.Assert(s.repo.SparseCheckoutInit(false), IsNil)
+	c.Assert(s.repo.SparseCheckoutSet("/*", "!/*/", "/a/", "!/a/b/"), IsNil)
+	c.Assert(s.checkedOut(c), DeepEquals, []string{"README", "a/x"})
+
+	matcher := operations.NewSparseMatcher(false, []string{"/*", "!/*/", "/a/", "!/a/b/"})
+	c.Assert(matcher.Includes("README"), Equals, true)
+	c.Assert(matcher.Includes("a/x"), Equals, true)
+	c.Assert(matcher.Includes("a/b/y"), Equals, false)
+	c.Assert(matcher.Includes("c/z"), Equals, false)
+
+	// as in git, patterns that match a file take precedence over
+	// those that match its directories.
+	matcher = operations.NewSparseMatcher(false, []string{"*.go", "!vendor/"})
+	c.Assert(matcher.Includes("main.go"), Equals, true)
+	c.Assert(matcher.Includes("pkg/util.go"), Equals, true)
+	c.Assert(matcher.Includes("vendor/lib.go"), Equals, true)
+	c.Assert(matcher.Includes("README"), Equals, false)
+
+	matcher = operations.NewSparseMatcher(false, []string{"*.go", "!vendor/**"})
+	c.Assert(matcher.Includes("vendor/lib.go"), Equals, false)
+}
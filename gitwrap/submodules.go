@@ -0,0 +1,213 @@
+package gitwrap
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/states"
+	"github.com/tychoish/grip"
+)
+
+func (self *repository) SetRecurseSubmodules(recurse bool) {
+	self.recurseSubmodules = recurse
+}
+
+// updateRecursiveSubmodules brings submodules in line with the
+// superproject after operations that modify the working tree, but
+// only when the repository is configured to recurse into submodules.
+func (self *repository) updateRecursiveSubmodules() error {
+	if !self.recurseSubmodules {
+		return nil
+	}
+
+	err := self.checkGitCommand("submodule", "update", "--init", "--recursive")
+	if err != nil {
+		self.state = states.IncompleteOperation
+	}
+
+	return err
+}
+
+func (self *repository) Submodules() ([]*operations.Submodule, error) {
+	if !self.exists {
+		return nil, fmt.Errorf("no repository exists at %s", self.path)
+	}
+
+	paths, err := self.runGitCommand("config", "--file", ".gitmodules",
+		"--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		// git config exits non-zero when there are no matching
+		// keys, which means there are no submodules.
+		return []*operations.Submodule{}, nil
+	}
+
+	checkouts, err := self.submoduleStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := self.submoduleStatus("--cached")
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []*operations.Submodule
+	for _, line := range paths {
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		sub := &operations.Submodule{
+			Name: strings.TrimSuffix(strings.TrimPrefix(parts[0], "submodule."), ".path"),
+			Path: parts[1],
+		}
+
+		sub.URL = self.submoduleURL(sub.Name)
+		sub.Recorded = recorded[sub.Path].sha
+
+		status, ok := checkouts[sub.Path]
+		if ok && status.flag != '-' {
+			sub.Status |= operations.SubmoduleInitialized
+			sub.Checkout = status.sha
+
+			if status.flag == '+' || status.flag == 'U' {
+				sub.Status |= operations.SubmoduleOutOfDate
+			}
+
+			changes, err := self.runGitCommand("-C", sub.Path, "status", "--porcelain")
+			if err == nil && len(changes) > 0 && changes[0] != "" {
+				sub.Status |= operations.SubmoduleDirty
+			}
+		}
+
+		modules = append(modules, sub)
+	}
+
+	return modules, nil
+}
+
+type submoduleState struct {
+	flag byte
+	sha  string
+}
+
+// submoduleStatus parses the output of "git submodule status", which
+// has one line per submodule, in the form "<flag><sha> <path>
+// (<describe>)", into a map of paths to the submodule's state.
+func (self *repository) submoduleStatus(args ...string) (map[string]submoduleState, error) {
+	// the first character of each line is significant, so the
+	// output must not be trimmed.
+	output, err := self.outputGitCommand(append([]string{"submodule", "status"}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("problem getting submodule status: %s", err)
+	}
+
+	status := make(map[string]submoduleState)
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) < 2 {
+			continue
+		}
+
+		fields := strings.Fields(line[1:])
+		if len(fields) < 2 {
+			continue
+		}
+
+		status[fields[1]] = submoduleState{flag: line[0], sha: fields[0]}
+	}
+
+	return status, nil
+}
+
+// submoduleURL returns the URL for a submodule, preferring the value
+// in the repository's configuration (which only exists once the
+// submodule is initialized) to the value in .gitmodules.
+func (self *repository) submoduleURL(name string) string {
+	key := fmt.Sprintf("submodule.%s.url", name)
+
+	url, err := self.runGitCommand("config", "--get", key)
+	if err == nil {
+		return url[0]
+	}
+
+	url, err = self.runGitCommand("config", "--file", ".gitmodules", "--get", key)
+	if err == nil {
+		return url[0]
+	}
+
+	return ""
+}
+
+func (self *repository) SubmoduleInit(paths ...string) error {
+	args := append([]string{"submodule", "init", "--"}, paths...)
+
+	err := self.checkGitCommand(args...)
+	if err != nil {
+		self.state = states.FailedOperation
+	}
+
+	return err
+}
+
+// SubmoduleUpdate checks out the recorded commit in each initialized
+// submodule; uninitialized submodules are skipped. When recursive,
+// the submodules nested within each updated submodule are initialized
+// and updated as well, as there is no other way to initialize them.
+func (self *repository) SubmoduleUpdate(recursive bool, paths ...string) error {
+	args := append([]string{"submodule", "update", "--"}, paths...)
+
+	err := self.checkGitCommand(args...)
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	if !recursive {
+		return nil
+	}
+
+	modules, err := self.Submodules()
+	if err != nil {
+		self.state = states.IncompleteOperation
+		return err
+	}
+
+	filter := make(map[string]bool)
+	for _, p := range paths {
+		filter[filepath.Clean(p)] = true
+	}
+
+	catcher := grip.NewCatcher()
+	for _, sub := range modules {
+		if !sub.IsInitialized() || (len(filter) > 0 && !filter[filepath.Clean(sub.Path)]) {
+			continue
+		}
+
+		catcher.Add(self.checkGitCommand("-C", sub.Path, "submodule", "update", "--init", "--recursive"))
+	}
+
+	if catcher.HasErrors() {
+		self.state = states.PartialOperation
+	}
+
+	return catcher.Resolve()
+}
+
+func (self *repository) SubmoduleSync(recursive bool) error {
+	var err error
+
+	if recursive {
+		err = self.checkGitCommand("submodule", "sync", "--recursive")
+	} else {
+		err = self.checkGitCommand("submodule", "sync")
+	}
+
+	if err != nil {
+		self.state = states.FailedOperation
+	}
+
+	return err
+}
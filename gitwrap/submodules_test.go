@@ -0,0 +1,148 @@
+package gitwrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type SubmoduleSuite struct {
+	inner *repository
+	lib   *repository
+	super *repository
+	repo  *repository
+}
+
+var _ = Suite(&SubmoduleSuite{})
+
+// SetUpSuite builds a superproject with a "lib" submodule, which
+// itself has an "inner" submodule.
+func (s *SubmoduleSuite) SetUpSuite(c *C) {
+	// submodules in these tests are cloned from local paths,
+	// which git refuses to do by default.
+	os.Setenv("GIT_CONFIG_COUNT", "1")
+	os.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	os.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	s.inner = newTestRepository(c)
+	s.commitFile(c, s.inner, "inner.txt", "inner")
+
+	s.lib = newTestRepository(c)
+	s.commitFile(c, s.lib, "lib.txt", "one")
+	c.Assert(s.lib.checkGitCommand("submodule", "add", s.inner.path, "inner"), IsNil)
+	c.Assert(s.lib.Commit("add inner"), IsNil)
+
+	s.super = newTestRepository(c)
+	c.Assert(s.super.checkGitCommand("submodule", "add", s.lib.path, "lib"), IsNil)
+	c.Assert(s.super.Commit("add lib"), IsNil)
+}
+
+func (s *SubmoduleSuite) TearDownSuite(c *C) {
+	os.Unsetenv("GIT_CONFIG_COUNT")
+	os.Unsetenv("GIT_CONFIG_KEY_0")
+	os.Unsetenv("GIT_CONFIG_VALUE_0")
+}
+
+func (s *SubmoduleSuite) SetUpTest(c *C) {
+	s.repo = NewRepository(filepath.Join(c.MkDir(), "clone"))
+	c.Assert(s.repo.Clone(s.super.path, "master"), IsNil)
+}
+
+func (s *SubmoduleSuite) commitFile(c *C, repo *repository, name, contents string) {
+	c.Assert(ioutil.WriteFile(filepath.Join(repo.path, name), []byte(contents), 0644), IsNil)
+	c.Assert(repo.Stage(name), IsNil)
+	c.Assert(repo.Commit("update "+name), IsNil)
+}
+
+func (s *SubmoduleSuite) exists(parts ...string) bool {
+	_, err := os.Stat(filepath.Join(append([]string{s.repo.path}, parts...)...))
+	return err == nil
+}
+
+func (s *SubmoduleSuite) TestInitAndUpdate(c *C) {
+	modules, err := s.repo.Submodules()
+	c.Assert(err, IsNil)
+	c.Assert(modules, HasLen, 1)
+	c.Assert(modules[0].Name, Equals, "lib")
+	c.Assert(modules[0].Path, Equals, "lib")
+	c.Assert(modules[0].URL, Equals, s.lib.path)
+	c.Assert(modules[0].IsInitialized(), Equals, false)
+	c.Assert(modules[0].Recorded, Not(Equals), "")
+
+	c.Logf("updating skips submodules that are not initialized")
+	c.Assert(s.repo.SubmoduleUpdate(true), IsNil)
+	c.Assert(s.exists("lib", "lib.txt"), Equals, false)
+
+	c.Assert(s.repo.SubmoduleInit(), IsNil)
+	c.Assert(s.repo.SubmoduleUpdate(false), IsNil)
+	c.Assert(s.exists("lib", "lib.txt"), Equals, true)
+	c.Assert(s.exists("lib", "inner", "inner.txt"), Equals, false)
+
+	modules, err = s.repo.Submodules()
+	c.Assert(err, IsNil)
+	c.Assert(modules[0].IsInitialized(), Equals, true)
+	c.Assert(modules[0].IsOutOfDate(), Equals, false)
+	c.Assert(modules[0].IsDirty(), Equals, false)
+	c.Assert(modules[0].Checkout, Equals, modules[0].Recorded)
+
+	c.Logf("a recursive update initializes nested submodules")
+	c.Assert(s.repo.SubmoduleUpdate(true, "lib"), IsNil)
+	c.Assert(s.exists("lib", "inner", "inner.txt"), Equals, true)
+}
+
+func (s *SubmoduleSuite) TestStatus(c *C) {
+	c.Assert(s.repo.SubmoduleInit("lib"), IsNil)
+	c.Assert(s.repo.SubmoduleUpdate(false, "lib"), IsNil)
+
+	c.Assert(ioutil.WriteFile(filepath.Join(s.repo.path, "lib", "lib.txt"), []byte("changed"), 0644), IsNil)
+	modules, err := s.repo.Submodules()
+	c.Assert(err, IsNil)
+	c.Assert(modules[0].IsDirty(), Equals, true)
+	c.Assert(modules[0].IsOutOfDate(), Equals, false)
+
+	sub := NewRepository(filepath.Join(s.repo.path, "lib"))
+	c.Assert(sub.checkGitCommand("config", "user.name", "Gitgone"), IsNil)
+	c.Assert(sub.checkGitCommand("config", "user.email", "gitgone@example.com"), IsNil)
+	c.Assert(sub.Stage("lib.txt"), IsNil)
+	c.Assert(sub.Commit("local change"), IsNil)
+
+	modules, err = s.repo.Submodules()
+	c.Assert(err, IsNil)
+	c.Assert(modules[0].IsDirty(), Equals, false)
+	c.Assert(modules[0].IsOutOfDate(), Equals, true)
+	c.Assert(modules[0].Checkout, Not(Equals), modules[0].Recorded)
+
+	c.Logf("updating restores the recorded commit")
+	c.Assert(s.repo.SubmoduleUpdate(false), IsNil)
+	modules, err = s.repo.Submodules()
+	c.Assert(err, IsNil)
+	c.Assert(modules[0].IsOutOfDate(), Equals, false)
+}
+
+func (s *SubmoduleSuite) TestSync(c *C) {
+	c.Assert(s.repo.SubmoduleInit(), IsNil)
+	c.Assert(s.repo.SubmoduleUpdate(true), IsNil)
+
+	moved := filepath.Join(c.MkDir(), "lib")
+	c.Assert(s.repo.checkGitCommand("config", "--file", ".gitmodules", "submodule.lib.url", moved), IsNil)
+	c.Assert(s.repo.SubmoduleSync(true), IsNil)
+
+	url, err := s.repo.Config().Get("submodule.lib.url")
+	c.Assert(err, IsNil)
+	c.Assert(url, Equals, moved)
+
+	modules, err := s.repo.Submodules()
+	c.Assert(err, IsNil)
+	c.Assert(modules[0].URL, Equals, moved)
+}
+
+func (s *SubmoduleSuite) TestMissingSubmodule(c *C) {
+	c.Assert(s.repo.SubmoduleInit("missing"), NotNil)
+
+	empty := newTestRepository(c)
+	modules, err := empty.Submodules()
+	c.Assert(err, IsNil)
+	c.Assert(modules, HasLen, 0)
+}
@@ -0,0 +1,142 @@
+package gitwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tychoish/gitgone/signing"
+	"github.com/tychoish/gitgone/states"
+	"github.com/tychoish/grip"
+)
+
+func tagRef(name string) string {
+	return fmt.Sprintf("refs/tags/%s", name)
+}
+
+func (self *repository) CreateTag(name, rev, message string, force bool) error {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	var err error
+	if message != "" && self.signer != nil {
+		err = self.createSignedTag(name, rev, message, force)
+	} else {
+		args := []string{"tag"}
+		if force {
+			args = append(args, "--force")
+		}
+		if message != "" {
+			args = append(args, "--annotate", "--message", message)
+		}
+		args = append(args, name, rev)
+
+		err = self.checkGitCommand(args...)
+	}
+
+	if err != nil {
+		self.state = states.FailedOperation
+	}
+
+	return err
+}
+
+// createSignedTag writes a tag object with a signature from the
+// repository's signer, rather than the signing program that git is
+// configured to use.
+func (self *repository) createSignedTag(name, rev, message string, force bool) error {
+	if err := self.checkGitCommand("check-ref-format", tagRef(name)); err != nil {
+		return fmt.Errorf("'%s' is not a valid tag name", name)
+	}
+
+	commit, err := self.getRef(rev + "^{commit}")
+	if err != nil {
+		return fmt.Errorf("could not resolve '%s' to a commit", rev)
+	}
+
+	tagger, err := self.getIdent("GIT_COMMITTER_IDENT")
+	if err != nil {
+		return err
+	}
+
+	data, err := signing.SignTag(&signing.Tag{
+		Object:     commit,
+		ObjectType: "commit",
+		Name:       name,
+		Tagger:     tagger,
+		Message:    message,
+	}, self.signer)
+	if err != nil {
+		return err
+	}
+
+	output, err := self.inputGitCommand(data, "mktag")
+	if err != nil {
+		return fmt.Errorf("could not write tag %s: %s", name, strings.Join(output, "\n"))
+	}
+
+	args := []string{"update-ref", "--create-reflog", "-m", "tag: tagging " + commit, tagRef(name), output[0]}
+	if !force {
+		// an empty old value requires that the tag not exist
+		args = append(args, "")
+	}
+
+	output, err = self.runGitCommand(args...)
+	if err != nil {
+		return fmt.Errorf("could not create tag %s: %s", name, strings.Join(output, "\n"))
+	}
+
+	return nil
+}
+
+func (self *repository) DeleteTag(name string) error {
+	return self.checkGitCommand("tag", "--delete", name)
+}
+
+func (self *repository) IsTagged(name, rev string, lightweight bool) bool {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	objType, err := self.runGitCommand("cat-file", "-t", tagRef(name))
+	if err != nil {
+		return false
+	}
+
+	if lightweight == (objType[0] == "tag") {
+		return false
+	}
+
+	tagged, err := self.getRef(tagRef(name) + "^{commit}")
+	if err != nil {
+		return false
+	}
+
+	commit, err := self.getRef(rev + "^{commit}")
+	if err != nil {
+		grip.CatchError(err)
+		return false
+	}
+
+	return tagged == commit
+}
+
+// VerifyTag checks the signature of an annotated tag. Lightweight and
+// unsigned tags are reported as unsigned.
+func (self *repository) VerifyTag(name string) (*signing.Verification, error) {
+	objType, err := self.runGitCommand("cat-file", "-t", tagRef(name))
+	if err != nil {
+		return nil, fmt.Errorf("tag %s does not exist", name)
+	}
+
+	if objType[0] != "tag" {
+		return &signing.Verification{Status: signing.Unsigned}, nil
+	}
+
+	data, err := self.outputGitCommand("cat-file", "tag", tagRef(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return signing.VerifyTag(data, self.getVerifier())
+}
@@ -0,0 +1,76 @@
+package gitwrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/tychoish/gitgone/config"
+	"github.com/tychoish/gitgone/signing"
+	. "gopkg.in/check.v1"
+)
+
+type TagSuite struct {
+	repo *repository
+}
+
+var _ = Suite(&TagSuite{})
+
+func (s *TagSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "first"), IsNil)
+	c.Assert(s.repo.checkGitCommand("commit", "--allow-empty", "-m", "second"), IsNil)
+}
+
+func (s *TagSuite) TestIsTagged(c *C) {
+	c.Assert(s.repo.CreateTag("light", "", "", false), IsNil)
+	c.Assert(s.repo.CreateTag("annotated", "HEAD~1", "release", false), IsNil)
+
+	c.Assert(s.repo.IsTagged("light", "", true), Equals, true)
+	c.Assert(s.repo.IsTagged("light", "HEAD", false), Equals, false)
+	c.Assert(s.repo.IsTagged("light", "HEAD~1", true), Equals, false)
+
+	c.Assert(s.repo.IsTagged("annotated", "HEAD~1", false), Equals, true)
+	c.Assert(s.repo.IsTagged("annotated", "HEAD~1", true), Equals, false)
+	c.Assert(s.repo.IsTagged("annotated", "HEAD", false), Equals, false)
+
+	c.Assert(s.repo.CreateTag("annotated", "", "moved", false), NotNil)
+	c.Assert(s.repo.CreateTag("annotated", "", "moved", true), IsNil)
+	c.Assert(s.repo.IsTagged("annotated", "HEAD", false), Equals, true)
+
+	c.Assert(s.repo.IsTagged("missing", "HEAD", true), Equals, false)
+}
+
+func (s *TagSuite) TestSignedTags(c *C) {
+	dir := c.MkDir()
+	key := filepath.Join(dir, "id_ed25519")
+	output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput()
+	c.Assert(err, IsNil, Commentf("%s", output))
+
+	public, err := ioutil.ReadFile(key + ".pub")
+	c.Assert(err, IsNil)
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	c.Assert(ioutil.WriteFile(allowedSigners, []byte(fmt.Sprintf("gitgone@example.com %s", public)), 0644), IsNil)
+	c.Assert(s.repo.Config().Set(config.Local, "gpg.ssh.allowedSignersFile", allowedSigners), IsNil)
+
+	c.Assert(s.repo.CreateTag("unsigned", "", "release", false), IsNil)
+	result, err := s.repo.VerifyTag("unsigned")
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, signing.Unsigned)
+
+	s.repo.SetSigner(signing.SSHKey(key))
+	c.Assert(s.repo.CreateTag("signed", "", "release", false), IsNil)
+	c.Assert(s.repo.CreateTag("signed", "HEAD~1", "release", false), NotNil)
+	c.Assert(s.repo.CreateTag("signed", "HEAD~1", "release", true), IsNil)
+	c.Assert(s.repo.IsTagged("signed", "HEAD~1", false), Equals, true)
+
+	result, err = s.repo.VerifyTag("signed")
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, signing.Good)
+	c.Assert(result.Format, Equals, signing.SSH)
+	c.Assert(result.Identity, Equals, "gitgone@example.com")
+
+	// git accepts the signature as well
+	c.Assert(s.repo.checkGitCommand("-c", "gpg.format=ssh", "verify-tag", "signed"), IsNil)
+}
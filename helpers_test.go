@@ -0,0 +1,28 @@
+package gitgone
+
+import (
+	"os/exec"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// newTestRepository initializes a repository in a temporary directory
+// using the git command line backend, with a committer identity so
+// that tests can commit.
+func newTestRepository(c *C, bare bool) *RepositoryManager {
+	repo := NewWrappedRepository(c.MkDir())
+	c.Assert(repo.Init(bare, "master", ""), IsNil)
+	c.Assert(repo.SetIdentity("Gitgone", "gitgone@example.com"), IsNil)
+	return repo
+}
+
+// git runs a git command in the repository, independently of either
+// backend, and returns its trimmed output.
+func git(c *C, repo Repository, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo.Path()
+	output, err := cmd.CombinedOutput()
+	c.Assert(err, IsNil, Commentf("%s", output))
+	return strings.TrimSpace(string(output))
+}
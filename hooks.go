@@ -0,0 +1,96 @@
+package gitgone
+
+import (
+	"github.com/tychoish/gitgone/hooks"
+	"github.com/tychoish/gitgone/operations"
+)
+
+// RegisterHook adds an in-process hook that runs around the
+// RepositoryManager's commit, checkout, and push operations. Pre-
+// hooks, and commit-msg hooks, run before the repository's hook
+// scripts, and post- hooks run after them. Hooks run in the order
+// they are registered.
+//
+// Pre-commit, commit-msg, and pre-push hooks can stop an operation
+// by returning an error, and commit-msg and pre-push hooks can modify
+// the commit message or the pushed refspecs. Errors from post-commit
+// and post-checkout hooks are returned after the operation completes.
+func (self *RepositoryManager) RegisterHook(hook hooks.Type, fn hooks.Func) {
+	if self.hooks == nil {
+		self.hooks = make(map[hooks.Type][]hooks.Func)
+	}
+
+	self.hooks[hook] = append(self.hooks[hook], fn)
+}
+
+func (self *RepositoryManager) runHooks(ctx *hooks.Context) error {
+	for _, fn := range self.hooks[ctx.Hook] {
+		if err := fn(ctx); err != nil {
+			return &hooks.RejectedError{Hook: ctx.Hook, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (self *RepositoryManager) commitWithHooks(message string, commit func(string) error) error {
+	ctx := &hooks.Context{Hook: hooks.PreCommit, Message: message}
+	if err := self.runHooks(ctx); err != nil {
+		return err
+	}
+
+	ctx.Hook = hooks.CommitMsg
+	if err := self.runHooks(ctx); err != nil {
+		return err
+	}
+
+	if err := commit(ctx.Message); err != nil {
+		return err
+	}
+
+	ctx.Hook = hooks.PostCommit
+	return self.runHooks(ctx)
+}
+
+func (self *RepositoryManager) Commit(message string) error {
+	return self.commitWithHooks(message, self.Repository.Commit)
+}
+
+func (self *RepositoryManager) CommitAll(message string) error {
+	return self.commitWithHooks(message, self.Repository.CommitAll)
+}
+
+func (self *RepositoryManager) Amend(message string) error {
+	return self.commitWithHooks(message, self.Repository.Amend)
+}
+
+func (self *RepositoryManager) AmendAll(message string) error {
+	return self.commitWithHooks(message, self.Repository.AmendAll)
+}
+
+func (self *RepositoryManager) Checkout(ref string) error {
+	if err := self.Repository.Checkout(ref); err != nil {
+		return err
+	}
+
+	return self.runHooks(&hooks.Context{Hook: hooks.PostCheckout, Ref: ref})
+}
+
+func (self *RepositoryManager) Push(remote, branch string) error {
+	_, err := self.PushWithOptions(operations.PushOptions{
+		Remote:   remote,
+		Refspecs: []string{branch},
+	})
+
+	return err
+}
+
+func (self *RepositoryManager) PushWithOptions(opts operations.PushOptions) (*operations.PushResult, error) {
+	ctx := &hooks.Context{Hook: hooks.PrePush, Remote: opts.Remote, Refspecs: opts.Refspecs}
+	if err := self.runHooks(ctx); err != nil {
+		return nil, err
+	}
+
+	opts.Refspecs = ctx.Refspecs
+	return self.Repository.PushWithOptions(opts)
+}
@@ -0,0 +1,149 @@
+// Package hooks runs repository hook scripts the way git does, so
+// that both Repository implementations run them consistently, and
+// defines the in-process hooks that a RepositoryManager runs around
+// its operations.
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Type names a hook, using the names of git's hook scripts.
+type Type string
+
+const (
+	PreCommit    Type = "pre-commit"
+	CommitMsg    Type = "commit-msg"
+	PostCommit   Type = "post-commit"
+	PrePush      Type = "pre-push"
+	PostCheckout Type = "post-checkout"
+)
+
+// IsPost returns true for hooks that run after their operation, and
+// so cannot prevent it.
+func (t Type) IsPost() bool {
+	return strings.HasPrefix(string(t), "post-")
+}
+
+// RejectedError is returned when a hook exits with an error, which
+// stops the operation for hooks that run before it.
+type RejectedError struct {
+	Hook   Type
+	Output string
+	Err    error
+}
+
+func (e *RejectedError) Error() string {
+	if e.Output == "" {
+		return fmt.Sprintf("%s hook failed: %s", e.Hook, e.Err)
+	}
+
+	return fmt.Sprintf("%s hook failed: %s", e.Hook, e.Output)
+}
+
+// IsRejected returns true if the error is a RejectedError.
+func IsRejected(err error) bool {
+	_, ok := err.(*RejectedError)
+	return ok
+}
+
+// Runner runs the hook scripts in a directory. Like git, it ignores
+// hooks that do not exist or are not executable, and runs hooks in
+// the top of the working tree, or in the git directory for bare
+// repositories.
+type Runner struct {
+	Dir     string
+	WorkDir string
+	Env     []string
+}
+
+// Exists returns true if the hook has an executable script.
+func (r *Runner) Exists(hook Type) bool {
+	info, err := os.Stat(filepath.Join(r.Dir, string(hook)))
+	if err != nil {
+		return false
+	}
+
+	return !info.IsDir() && info.Mode()&0111 != 0
+}
+
+// Run runs a hook with the given input and arguments, returning a
+// RejectedError if it fails.
+func (r *Runner) Run(hook Type, stdin []byte, args ...string) error {
+	if !r.Exists(hook) {
+		return nil
+	}
+
+	cmd := exec.Command(filepath.Join(r.Dir, string(hook)), args...)
+	cmd.Dir = r.WorkDir
+	cmd.Env = append(os.Environ(), r.Env...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return &RejectedError{Hook: hook, Output: strings.TrimSpace(string(output)), Err: err}
+	}
+
+	return nil
+}
+
+// CommitMessage runs the commit-msg hook, which may rewrite the
+// message, and returns the resulting message. Like git, the hook
+// sees the message with a trailing newline.
+func (r *Runner) CommitMessage(message string) (string, error) {
+	if !r.Exists(CommitMsg) {
+		return message, nil
+	}
+
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	file, err := ioutil.TempFile("", "gitgone-commit-msg-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(message)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = r.Run(CommitMsg, nil, file.Name()); err != nil {
+		return "", err
+	}
+
+	updated, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return string(updated), nil
+}
+
+// Context describes the operation that an in-process hook runs for.
+// Hooks that run before an operation may change the Message of a
+// commit, or the Refspecs of a push.
+type Context struct {
+	Hook     Type
+	Message  string
+	Remote   string
+	Refspecs []string
+	Ref      string
+}
+
+// Func is an in-process hook. Errors returned by hooks that run
+// before an operation stop the operation.
+type Func func(*Context) error
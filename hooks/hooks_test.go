@@ -0,0 +1,62 @@
+package hooks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type HooksSuite struct {
+	runner *Runner
+}
+
+var _ = Suite(&HooksSuite{})
+
+func (s *HooksSuite) SetUpTest(c *C) {
+	dir := c.MkDir()
+	s.runner = &Runner{Dir: dir, WorkDir: dir, Env: []string{"GITGONE_HOOK_TEST=1"}}
+}
+
+func (s *HooksSuite) writeHook(c *C, hook Type, script string, mode os.FileMode) {
+	fn := filepath.Join(s.runner.Dir, string(hook))
+	c.Assert(ioutil.WriteFile(fn, []byte("#!/bin/sh\n"+script+"\n"), 0644), IsNil)
+	c.Assert(os.Chmod(fn, mode), IsNil)
+}
+
+func (s *HooksSuite) TestRun(c *C) {
+	c.Assert(s.runner.Exists(PreCommit), Equals, false)
+	c.Assert(s.runner.Run(PreCommit, nil), IsNil)
+
+	// like git, ignore hooks that are not executable
+	s.writeHook(c, PreCommit, "exit 1", 0644)
+	c.Assert(s.runner.Exists(PreCommit), Equals, false)
+	c.Assert(s.runner.Run(PreCommit, nil), IsNil)
+
+	s.writeHook(c, PreCommit, `echo "rejected $1 $GITGONE_HOOK_TEST"; exit 1`, 0755)
+	err := s.runner.Run(PreCommit, nil, "arg")
+	c.Assert(IsRejected(err), Equals, true)
+	c.Assert(err, ErrorMatches, "pre-commit hook failed: rejected arg 1")
+
+	s.writeHook(c, PrePush, `read line; test "$line" = "refs/heads/master"`, 0755)
+	c.Assert(s.runner.Run(PrePush, []byte("refs/heads/master\n")), IsNil)
+	c.Assert(s.runner.Run(PrePush, []byte("refs/heads/other\n")), NotNil)
+
+	c.Assert(PostCommit.IsPost(), Equals, true)
+	c.Assert(CommitMsg.IsPost(), Equals, false)
+}
+
+func (s *HooksSuite) TestCommitMessage(c *C) {
+	message, err := s.runner.CommitMessage("unchanged\n")
+	c.Assert(err, IsNil)
+	c.Assert(message, Equals, "unchanged\n")
+
+	s.writeHook(c, CommitMsg, `echo "Reviewed-by: Gitgone" >> "$1"`, 0755)
+	message, err = s.runner.CommitMessage("subject\n\n")
+	c.Assert(err, IsNil)
+	c.Assert(message, Equals, "subject\n\nReviewed-by: Gitgone\n")
+}
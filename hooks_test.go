@@ -0,0 +1,97 @@
+package gitgone
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tychoish/gitgone/hooks"
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/signing"
+	. "gopkg.in/check.v1"
+)
+
+type HooksSuite struct {
+	repo *RepositoryManager
+}
+
+var _ = Suite(&HooksSuite{})
+
+func (s *HooksSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c, false)
+
+	s.writeHook(c, hooks.PreCommit, `test ! -e BLOCK`)
+	s.writeHook(c, hooks.CommitMsg, `echo "Hooked: yes" >> "$1"`)
+	s.writeHook(c, hooks.PostCommit, `git rev-parse HEAD > .git/post-commit`)
+}
+
+func (s *HooksSuite) writeHook(c *C, hook hooks.Type, script string) {
+	fn := filepath.Join(s.repo.Path(), ".git", "hooks", string(hook))
+	c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(fn, []byte("#!/bin/sh\n"+script+"\n"), 0755), IsNil)
+}
+
+func (s *HooksSuite) commitFile(c *C, name, message string) error {
+	c.Assert(ioutil.WriteFile(filepath.Join(s.repo.Path(), name), []byte(name+"\n"), 0644), IsNil)
+	c.Assert(s.repo.Stage(name), IsNil)
+	return s.repo.Commit(message)
+}
+
+func (s *HooksSuite) TestScriptHooks(c *C) {
+	for idx, signer := range []signing.Signer{nil, signing.NewTestSigner("gitgone@example.com")} {
+		s.repo.SetSigner(signer)
+
+		c.Assert(s.commitFile(c, fmt.Sprint("file-", idx), "change file"), IsNil)
+		c.Assert(git(c, s.repo, "log", "-1", "--format=%B"), Equals, "change file\nHooked: yes")
+
+		marker, err := ioutil.ReadFile(filepath.Join(s.repo.Path(), ".git", "post-commit"))
+		c.Assert(err, IsNil)
+		c.Assert(strings.TrimSpace(string(marker)), Equals, git(c, s.repo, "rev-parse", "HEAD"))
+
+		c.Assert(s.commitFile(c, "BLOCK", "blocked"), NotNil)
+
+		s.repo.SetSkipHooks(true)
+		c.Assert(s.repo.Commit("unhooked"), IsNil)
+		c.Assert(git(c, s.repo, "log", "-1", "--format=%B"), Equals, "unhooked")
+		c.Assert(git(c, s.repo, "rm", "--quiet", "BLOCK"), Equals, "")
+		c.Assert(s.repo.Commit("remove block"), IsNil)
+		s.repo.SetSkipHooks(false)
+	}
+}
+
+func (s *HooksSuite) TestGoHooks(c *C) {
+	var checkouts []string
+	s.repo.RegisterHook(hooks.PreCommit, func(ctx *hooks.Context) error {
+		if strings.HasPrefix(ctx.Message, "WIP") {
+			return errors.New("work in progress")
+		}
+		return nil
+	})
+	s.repo.RegisterHook(hooks.CommitMsg, func(ctx *hooks.Context) error {
+		ctx.Message = "[gitgone] " + ctx.Message
+		return nil
+	})
+	s.repo.RegisterHook(hooks.PostCheckout, func(ctx *hooks.Context) error {
+		checkouts = append(checkouts, ctx.Ref)
+		return nil
+	})
+	s.repo.RegisterHook(hooks.PrePush, func(ctx *hooks.Context) error {
+		return errors.New("no pushing")
+	})
+
+	err := s.commitFile(c, "file", "WIP: not yet")
+	c.Assert(hooks.IsRejected(err), Equals, true)
+	c.Assert(err, ErrorMatches, "pre-commit hook failed: work in progress")
+
+	c.Assert(s.repo.Commit("ready"), IsNil)
+	c.Assert(git(c, s.repo, "log", "-1", "--format=%B"), Equals, "[gitgone] ready\nHooked: yes")
+
+	c.Assert(s.repo.CheckoutBranch("feature", "master"), IsNil)
+	c.Assert(checkouts, DeepEquals, []string{"feature"})
+
+	_, err = s.repo.PushWithOptions(operations.PushOptions{Remote: "origin", Refspecs: []string{"feature"}})
+	c.Assert(hooks.IsRejected(err), Equals, true)
+}
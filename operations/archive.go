@@ -0,0 +1,22 @@
+package operations
+
+import "fmt"
+
+// ArchiveFormat identifies the container format of an archive.
+type ArchiveFormat string
+
+const (
+	ArchiveTar     ArchiveFormat = "tar"
+	ArchiveTarGzip ArchiveFormat = "tar.gz"
+	ArchiveZip     ArchiveFormat = "zip"
+)
+
+// Validate returns an error if the format is not supported.
+func (f ArchiveFormat) Validate() error {
+	switch f {
+	case ArchiveTar, ArchiveTarGzip, ArchiveZip:
+		return nil
+	default:
+		return fmt.Errorf("'%s' is not a supported archive format", f)
+	}
+}
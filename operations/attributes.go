@@ -0,0 +1,165 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// AttributeState describes whether a gitattributes attribute is set
+// for a path.
+type AttributeState int
+
+const (
+	AttributeUnspecified AttributeState = iota
+	AttributeSet
+	AttributeUnset
+	AttributeValue
+)
+
+// Attribute is the state of a single gitattributes attribute for a
+// path. Value is only meaningful for AttributeValue.
+type Attribute struct {
+	Name  string
+	State AttributeState
+	Value string
+}
+
+// ParseAttribute converts a value in the form "git check-attr"
+// prints it to an Attribute.
+func ParseAttribute(name, value string) *Attribute {
+	switch value {
+	case "set":
+		return &Attribute{Name: name, State: AttributeSet}
+	case "unset":
+		return &Attribute{Name: name, State: AttributeUnset}
+	case "unspecified":
+		return &Attribute{Name: name, State: AttributeUnspecified}
+	default:
+		return &Attribute{Name: name, State: AttributeValue, Value: value}
+	}
+}
+
+// String returns the attribute's value in the form "git check-attr"
+// prints it.
+func (a *Attribute) String() string {
+	switch a.State {
+	case AttributeSet:
+		return "set"
+	case AttributeUnset:
+		return "unset"
+	case AttributeValue:
+		return a.Value
+	default:
+		return "unspecified"
+	}
+}
+
+// binaryMacro is the attribute macro that git defines for binary
+// files.
+var binaryMacro = []string{"-diff", "-merge", "-text"}
+
+// AttributeRule is a line from a gitattributes file, in the
+// directory Dir relative to the top of the repository.
+type AttributeRule struct {
+	Dir        string
+	Pattern    string
+	Attributes []*Attribute
+}
+
+// ParseAttributeRules parses the contents of a gitattributes file.
+// The built in "binary" macro is expanded, but other macros are not
+// supported.
+func ParseAttributeRules(dir string, contents []byte) []*AttributeRule {
+	var rules []*AttributeRule
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") {
+			continue
+		}
+
+		rule := &AttributeRule{Dir: dir, Pattern: fields[0]}
+		for _, field := range fields[1:] {
+			rule.Attributes = append(rule.Attributes, parseAttributeField(field))
+			if field == "binary" {
+				for _, macro := range binaryMacro {
+					rule.Attributes = append(rule.Attributes, parseAttributeField(macro))
+				}
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+func parseAttributeField(field string) *Attribute {
+	switch {
+	case strings.HasPrefix(field, "-"):
+		return &Attribute{Name: field[1:], State: AttributeUnset}
+	case strings.HasPrefix(field, "!"):
+		return &Attribute{Name: field[1:], State: AttributeUnspecified}
+	case strings.Contains(field, "="):
+		parts := strings.SplitN(field, "=", 2)
+		return &Attribute{Name: parts[0], State: AttributeValue, Value: parts[1]}
+	default:
+		return &Attribute{Name: field, State: AttributeSet}
+	}
+}
+
+// Matches follows the gitattributes pattern rules: patterns without a
+// slash match the name of a file at any depth below the directory of
+// the rule, and other patterns match the path relative to that
+// directory.
+func (r *AttributeRule) Matches(name string) bool {
+	if r.Dir != "" {
+		if !strings.HasPrefix(name, r.Dir+"/") {
+			return false
+		}
+		name = name[len(r.Dir)+1:]
+	}
+
+	return matchPattern(r.Pattern, name, false)
+}
+
+// ResolveAttributes applies rules, given in increasing order of
+// precedence, to a path. With no names, it returns every attribute
+// that is set, unset or has a value, sorted by name; otherwise it
+// returns the named attributes, in order.
+func ResolveAttributes(rules []*AttributeRule, name string, names ...string) []*Attribute {
+	state := map[string]*Attribute{}
+	for _, rule := range rules {
+		if !rule.Matches(name) {
+			continue
+		}
+
+		for _, attr := range rule.Attributes {
+			state[attr.Name] = attr
+		}
+	}
+
+	var attrs []*Attribute
+	if len(names) == 0 {
+		for _, attr := range state {
+			if attr.State != AttributeUnspecified {
+				attrs = append(attrs, attr)
+			}
+		}
+		sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+
+		return attrs
+	}
+
+	for _, name := range names {
+		if attr, ok := state[name]; ok {
+			attrs = append(attrs, attr)
+		} else {
+			attrs = append(attrs, &Attribute{Name: name})
+		}
+	}
+
+	return attrs
+}
@@ -0,0 +1,59 @@
+package operations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type AttributeSuite struct{}
+
+var _ = Suite(&AttributeSuite{})
+
+// TestMatches checks which paths a rule matches against "git
+// check-attr", with the rule in a gitattributes file in Dir.
+func (s *AttributeSuite) TestMatches(c *C) {
+	for _, test := range []struct {
+		dir     string
+		pattern string
+		name    string
+		matched bool
+	}{
+		{"", "*.txt", "a.txt", true},
+		{"", "*.txt", "docs/a.txt", true},
+		{"", "docs/*.txt", "docs/a.txt", true},
+		{"", "docs/*.txt", "docs/x/a.txt", false},
+		{"", "docs/**/*.md", "docs/a.md", true},
+		{"", "docs/**/*.md", "docs/x/y/a.md", true},
+		{"", "docs/**/*.md", "a.md", false},
+		{"", "docs/**/*.md", "other/docs/a.md", false},
+		{"", "src/**/gen/*", "src/gen/a", true},
+		{"", "src/**/gen/*", "src/x/gen/a", true},
+		{"", "src/**/gen/*", "src/gen/a/b", false},
+		{"", "**/tmp", "tmp", true},
+		{"", "**/tmp", "a/b/tmp", true},
+		{"", "/out/**", "out/a/b", true},
+		{"", "/out/**", "src/out/a", false},
+		{"", "build/", "build/x", false},
+		{"sub", "*.txt", "sub/a/b.txt", true},
+		{"sub", "**/b/*.txt", "sub/b/c.txt", true},
+		{"sub", "**/b/*.txt", "b/c.txt", false},
+		{"sub", "/a/**", "sub/a/b", true},
+	} {
+		comment := Commentf("%q in %q against %q", test.pattern, test.dir, test.name)
+		rule := ParseAttributeRules(test.dir, []byte(test.pattern+" check\n"))[0]
+		c.Check(rule.Matches(test.name), Equals, test.matched, comment)
+
+		path := newTestRepository(c)
+		fn := filepath.Join(path, filepath.FromSlash(test.dir), ".gitattributes")
+		c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fn, []byte(test.pattern+" check\n"), 0644), IsNil)
+
+		output := git(c, path, "check-attr", "check", "--", test.name)
+		c.Assert(output, HasLen, 1)
+		c.Check(strings.HasSuffix(output[0], ": set"), Equals, test.matched, comment)
+	}
+}
@@ -0,0 +1,54 @@
+package operations
+
+import "time"
+
+// BlameOptions control the attribution of lines by Blame. StartLine
+// and EndLine are one-based and inclusive, and restrict the blame to
+// a range of lines when set. IgnoreRevsFile names a file of commits,
+// such as reformatting commits, whose changes are attributed to
+// earlier commits instead.
+type BlameOptions struct {
+	StartLine        int
+	EndLine          int
+	IgnoreRevsFile   string
+	IgnoreWhitespace bool
+}
+
+// BlameHunk attributes a range of consecutive lines in a file to the
+// commit that last changed them. Start is the first line of the hunk
+// in the blamed revision of the file, and OriginalStart is the line
+// number in OriginalPath in that commit. Line numbers are one-based.
+type BlameHunk struct {
+	Commit        string
+	Author        string
+	AuthorEmail   string
+	AuthorTime    time.Time
+	OriginalPath  string
+	OriginalStart int
+	Start         int
+	Lines         int
+}
+
+// Contains returns true if the line, in the blamed revision of the
+// file, is part of the hunk.
+func (h *BlameHunk) Contains(line int) bool {
+	return line >= h.Start && line < h.Start+h.Lines
+}
+
+// BlameResult holds the hunks of a file in order.
+type BlameResult struct {
+	Path  string
+	Hunks []*BlameHunk
+}
+
+// Line returns the hunk for a line in the blamed revision of the
+// file, or nil if the line was not blamed.
+func (r *BlameResult) Line(line int) *BlameHunk {
+	for _, hunk := range r.Hunks {
+		if hunk.Contains(line) {
+			return hunk
+		}
+	}
+
+	return nil
+}
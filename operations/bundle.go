@@ -0,0 +1,106 @@
+package operations
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultBundleRefspec fetches the branches in a bundle into
+// remote-tracking refs, when no refspecs are given.
+const DefaultBundleRefspec = "+refs/heads/*:refs/remotes/bundle/*"
+
+const (
+	bundleV2Signature = "# v2 git bundle"
+	bundleV3Signature = "# v3 git bundle"
+)
+
+// BundleRef is a ref recorded in a bundle header. For prerequisites,
+// Name is the optional comment that follows the commit, which git
+// sets to the commit's summary.
+type BundleRef struct {
+	Commit string
+	Name   string
+}
+
+// Bundle describes the header of a git bundle: the refs it contains
+// and the commits a repository must have to fetch from it.
+type Bundle struct {
+	Version       int
+	Capabilities  []string
+	Refs          []*BundleRef
+	Prerequisites []*BundleRef
+}
+
+// ReadBundleHeader parses the header of a v2 or v3 bundle, leaving
+// the reader positioned at the start of the packfile when the reader
+// is a *bufio.Reader.
+func ReadBundleHeader(r io.Reader) (*Bundle, error) {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+
+	bundle := &Bundle{}
+	for lineNum := 0; ; lineNum++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("truncated bundle header: %s", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		if lineNum == 0 {
+			switch line {
+			case bundleV2Signature:
+				bundle.Version = 2
+			case bundleV3Signature:
+				bundle.Version = 3
+			default:
+				return nil, fmt.Errorf("not a git bundle")
+			}
+			continue
+		}
+
+		switch {
+		case line == "":
+			return bundle, nil
+		case strings.HasPrefix(line, "@") && bundle.Version == 3:
+			bundle.Capabilities = append(bundle.Capabilities, line[1:])
+		case strings.HasPrefix(line, "-"):
+			parts := strings.SplitN(line[1:], " ", 2)
+			ref := &BundleRef{Commit: parts[0]}
+			if len(parts) == 2 {
+				ref.Name = parts[1]
+			}
+			bundle.Prerequisites = append(bundle.Prerequisites, ref)
+		default:
+			parts := strings.SplitN(line, " ", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("malformed bundle ref '%s'", line)
+			}
+			bundle.Refs = append(bundle.Refs, &BundleRef{Commit: parts[0], Name: parts[1]})
+		}
+	}
+}
+
+// WriteBundleHeader writes a v2 bundle header, which must be
+// followed by a packfile containing the objects in the bundle.
+func WriteBundleHeader(w io.Writer, bundle *Bundle) error {
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintln(buf, bundleV2Signature)
+	for _, ref := range bundle.Prerequisites {
+		if ref.Name == "" {
+			fmt.Fprintf(buf, "-%s\n", ref.Commit)
+		} else {
+			fmt.Fprintf(buf, "-%s %s\n", ref.Commit, ref.Name)
+		}
+	}
+	for _, ref := range bundle.Refs {
+		fmt.Fprintf(buf, "%s %s\n", ref.Commit, ref.Name)
+	}
+	fmt.Fprintln(buf)
+
+	return buf.Flush()
+}
@@ -0,0 +1,15 @@
+package operations
+
+// CleanOptions control which files Clean removes. By default, Clean
+// removes untracked files that are not ignored, but leaves untracked
+// directories. Directories removes untracked directories as well.
+// IncludeIgnored also removes ignored files, and IgnoredOnly removes
+// only ignored files. DryRun reports the paths without removing them.
+// Pathspecs restrict the paths that are removed.
+type CleanOptions struct {
+	DryRun         bool
+	Directories    bool
+	IgnoredOnly    bool
+	IncludeIgnored bool
+	Pathspecs      []string
+}
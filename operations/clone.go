@@ -0,0 +1,42 @@
+package operations
+
+import "time"
+
+// CloneOptions control how a repository is cloned. The zero value
+// produces a full clone of the remote's default branch.
+type CloneOptions struct {
+	// Branch is checked out after the clone and, for single
+	// branch clones, is the only branch fetched.
+	Branch string
+
+	// Depth and ShallowSince create a shallow clone with history
+	// truncated to the given number of commits or to commits
+	// after the given time.
+	Depth        int
+	ShallowSince time.Time
+
+	// SingleBranch fetches only Branch, or the remote's default
+	// branch, rather than all branches. As with git, shallow
+	// clones are always single branch clones.
+	SingleBranch bool
+
+	// Filter requests a partial clone with the given object
+	// filter specification, e.g. "blob:none" or "tree:0".
+	Filter string
+
+	NoCheckout bool
+
+	// Mirror creates a bare repository that maps all refs from
+	// the remote directly onto local refs.
+	Mirror bool
+
+	// References are paths to local repositories used as
+	// alternate object stores to avoid fetching objects that
+	// already exist on disk.
+	References []string
+}
+
+// IsShallow returns true when the options produce a shallow clone.
+func (opts CloneOptions) IsShallow() bool {
+	return opts.Depth > 0 || !opts.ShallowSince.IsZero()
+}
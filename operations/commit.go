@@ -0,0 +1,88 @@
+package operations
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// FileMode is the mode of an entry in a git tree.
+type FileMode int
+
+const (
+	ModeRegular    FileMode = 0100644
+	ModeExecutable FileMode = 0100755
+	ModeSymlink    FileMode = 0120000
+	ModeTree       FileMode = 040000
+	ModeSubmodule  FileMode = 0160000
+)
+
+// IsFile returns true for modes of blobs: regular files, executable
+// files and symbolic links.
+func (m FileMode) IsFile() bool {
+	return m == ModeRegular || m == ModeExecutable || m == ModeSymlink
+}
+
+// FileChangeKind identifies the kind of change to a file.
+type FileChangeKind int
+
+const (
+	FileWrite FileChangeKind = iota
+	FileDelete
+	FileRename
+	FileSetMode
+)
+
+// FileChange is a change to a path in a tree. Writes replace the
+// contents of a file, keeping the mode of an existing file unless
+// Mode is set. Renames move a file or directory from the From path.
+type FileChange struct {
+	Kind     FileChangeKind
+	Path     string
+	From     string
+	Contents []byte
+	Mode     FileMode
+}
+
+// Identity is the author or committer of a commit. A zero When is
+// the time the commit is written.
+type Identity struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// CommitRequest describes a commit to write without a working tree.
+// The changes apply, in order, to the tree of the Parent revision, or
+// to an empty tree when there is no parent. A nil Author or Committer
+// uses the configured identity. When Ref is set, it is updated to the
+// new commit; with CompareAndSwap, only if its value is Expected, or
+// if it does not exist when Expected is empty.
+type CommitRequest struct {
+	Parent         string
+	Changes        []*FileChange
+	Author         *Identity
+	Committer      *Identity
+	Message        string
+	Ref            string
+	CompareAndSwap bool
+	Expected       string
+}
+
+// SplitTreePath cleans a path in a tree and returns its components.
+// Paths may not leave the tree or refer to a .git directory.
+func SplitTreePath(name string) ([]string, error) {
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." || part == ".git" {
+			return nil, fmt.Errorf("'%s' is not a valid path in a tree", name)
+		}
+	}
+
+	cleaned := strings.Trim(path.Clean("/"+name), "/")
+	if cleaned == "" {
+		return nil, fmt.Errorf("'%s' is not a valid path in a tree", name)
+	}
+
+	return strings.Split(cleaned, "/"), nil
+}
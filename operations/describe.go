@@ -0,0 +1,121 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoDescription is returned by Describe when no tag is reachable
+// from the revision.
+var ErrNoDescription = errors.New("no tags can describe the revision")
+
+// DescribeOptions control the tags that Describe considers. By
+// default only annotated tags are used; Tags includes lightweight
+// tags as well. Match and Exclude are glob patterns for tag names.
+// Abbrev is the length of the abbreviated commit id, seven when
+// unset. Dirty marks the description when the working tree has
+// changes, and only applies when describing HEAD.
+type DescribeOptions struct {
+	Tags    bool
+	Match   []string
+	Exclude []string
+	Abbrev  int
+	Dirty   bool
+}
+
+// AbbrevLength returns the length of abbreviated commit ids, which,
+// as in git, is at most the length of a full id.
+func (opts DescribeOptions) AbbrevLength() int {
+	switch {
+	case opts.Abbrev <= 0:
+		return 7
+	case opts.Abbrev > 40:
+		return 40
+	default:
+		return opts.Abbrev
+	}
+}
+
+// Description identifies a commit by the nearest tag, the number of
+// commits since the tag, and the abbreviated id of the commit.
+type Description struct {
+	Tag      string
+	Distance int
+	Commit   string
+	Dirty    bool
+}
+
+// String formats the description the way "git describe" does.
+func (d *Description) String() string {
+	out := d.Tag
+	if d.Distance > 0 {
+		out = fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, d.Commit)
+	}
+	if d.Dirty {
+		out += "-dirty"
+	}
+
+	return out
+}
+
+// Version is a semantic version, with the distance, commit and
+// working tree state of the description it was derived from.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Build      string
+
+	Distance int
+	Commit   string
+	Dirty    bool
+}
+
+var versionPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
+// ParseVersion parses a semantic version, with an optional "v"
+// prefix, such as a release tag.
+func ParseVersion(value string) (*Version, error) {
+	match := versionPattern.FindStringSubmatch(value)
+	if match == nil {
+		return nil, fmt.Errorf("'%s' is not a semantic version", value)
+	}
+
+	version := &Version{Prerelease: match[4], Build: match[5]}
+	version.Major, _ = strconv.Atoi(match[1])
+	version.Minor, _ = strconv.Atoi(match[2])
+	version.Patch, _ = strconv.Atoi(match[3])
+
+	return version, nil
+}
+
+// String formats the version. Commits after the tag, and changes in
+// the working tree, are recorded as build metadata, so that
+// "1.2.0" three commits later is "1.2.0+3.gabc1234".
+func (v *Version) String() string {
+	out := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		out += "-" + v.Prerelease
+	}
+
+	var build []string
+	if v.Build != "" {
+		build = append(build, v.Build)
+	}
+	if v.Distance > 0 {
+		build = append(build, strconv.Itoa(v.Distance), "g"+v.Commit)
+	}
+	if v.Dirty {
+		build = append(build, "dirty")
+	}
+	if len(build) > 0 {
+		out += "+" + strings.Join(build, ".")
+	}
+
+	return out
+}
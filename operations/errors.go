@@ -0,0 +1,22 @@
+package operations
+
+import "fmt"
+
+// UnsupportedError is returned when a Repository implementation
+// cannot perform an operation, or cannot honor one of the options
+// passed to an operation.
+type UnsupportedError struct {
+	Operation      string
+	Implementation string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is not supported by the %s implementation",
+		e.Operation, e.Implementation)
+}
+
+// IsUnsupported returns true if the error is an UnsupportedError.
+func IsUnsupported(err error) bool {
+	_, ok := err.(*UnsupportedError)
+	return ok
+}
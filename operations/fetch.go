@@ -0,0 +1,47 @@
+package operations
+
+// TagMode controls which tags a fetch downloads.
+type TagMode int
+
+const (
+	// TagsAuto fetches tags that point into the history being
+	// fetched, which is git's default behavior.
+	TagsAuto TagMode = iota
+	TagsAll
+	TagsNone
+)
+
+// FetchOptions describe a fetch from a single remote, or from every
+// remote when Remote is "all". Without Refspecs, the remote's
+// configured refspecs are used.
+type FetchOptions struct {
+	Remote   string
+	Refspecs []string
+	Prune    bool
+	Tags     TagMode
+	Depth    int
+}
+
+// RefUpdate describes a change to a single local ref. Old is empty
+// for refs that the operation created, and New is empty for refs
+// that it deleted.
+type RefUpdate struct {
+	Remote string
+	Ref    string
+	Old    string
+	New    string
+	Forced bool
+}
+
+func (u *RefUpdate) IsCreated() bool {
+	return u.Old == ""
+}
+
+func (u *RefUpdate) IsDeleted() bool {
+	return u.New == ""
+}
+
+// FetchResult lists the local refs changed by a fetch.
+type FetchResult struct {
+	Updates []*RefUpdate
+}
@@ -0,0 +1,19 @@
+package operations
+
+// GrepOptions control how Grep matches content. Patterns are regular
+// expressions, in the syntax of the regexp package, unless
+// FixedStrings is set. Pathspecs restrict the search to matching
+// paths, which may be directories or glob patterns.
+type GrepOptions struct {
+	FixedStrings bool
+	IgnoreCase   bool
+	Pathspecs    []string
+}
+
+// GrepMatch is a line that matches the pattern. Line is one-based,
+// and Text is the content of the line without the trailing newline.
+type GrepMatch struct {
+	Path string
+	Line int
+	Text string
+}
@@ -0,0 +1,45 @@
+package operations
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// newTestRepository creates a repository with a commit of the given
+// files, whose contents are their names.
+func newTestRepository(c *C, files ...string) string {
+	path := c.MkDir()
+	git(c, path, "init", "--quiet")
+
+	for _, name := range files {
+		fn := filepath.Join(path, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fn, []byte(name+"\n"), 0644), IsNil)
+	}
+
+	git(c, path, "add", "--force", ".")
+	git(c, path, "-c", "user.name=Gitgone", "-c", "user.email=gitgone@example.com",
+		"commit", "--quiet", "--allow-empty", "-m", "initial")
+
+	return path
+}
+
+// git runs a git command in a repository, and returns the lines of
+// its output.
+func git(c *C, path string, args ...string) []string {
+	output, err := exec.Command("git", append([]string{"-C", path}, args...)...).CombinedOutput()
+	c.Assert(err, IsNil, Commentf("git %s: %s", strings.Join(args, " "), output))
+
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,128 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// IgnoreResult reports whether a path is ignored, and the rule that
+// decided it. Source, Line and Pattern are empty when no rule
+// matches the path. A matching negated pattern, such as "!keep.log",
+// means that the path is not ignored.
+type IgnoreResult struct {
+	Path    string
+	Ignored bool
+	Source  string
+	Line    int
+	Pattern string
+}
+
+// IsMatched returns true if a rule matched the path.
+func (r *IgnoreResult) IsMatched() bool {
+	return r.Pattern != ""
+}
+
+// IgnoreRule is a pattern from a gitignore file, in the directory Dir
+// relative to the top of the repository.
+type IgnoreRule struct {
+	Source  string
+	Line    int
+	Dir     string
+	Pattern string
+}
+
+// ParseIgnoreRules parses the contents of a gitignore file.
+func ParseIgnoreRules(source, dir string, contents []byte) []*IgnoreRule {
+	var rules []*IgnoreRule
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimRight(scanner.Text(), " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		rules = append(rules, &IgnoreRule{Source: source, Line: line, Dir: dir, Pattern: pattern})
+	}
+
+	return rules
+}
+
+// IsNegated returns true for patterns that re-include paths.
+func (r *IgnoreRule) IsNegated() bool {
+	return strings.HasPrefix(r.Pattern, "!")
+}
+
+// matches returns true if the pattern matches the path, which is a
+// directory when dir is true, but not if it only matches one of the
+// path's parent directories.
+func (r *IgnoreRule) matches(name string, dir bool) bool {
+	if r.Dir != "" {
+		if !strings.HasPrefix(name, r.Dir+"/") {
+			return false
+		}
+		name = name[len(r.Dir)+1:]
+	}
+
+	return matchPattern(strings.TrimPrefix(r.Pattern, "!"), name, dir)
+}
+
+// MatchIgnoreRules returns the rule that decides whether a path is
+// ignored. As in git, files in an ignored directory cannot be
+// re-included, so this is the last rule that matches the first
+// ignored parent directory, or otherwise the last rule that matches
+// the path. Paths that end in a slash are directories.
+func MatchIgnoreRules(rules []*IgnoreRule, name string) *IgnoreRule {
+	dir := strings.HasSuffix(name, "/")
+	name = strings.TrimSuffix(name, "/")
+
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		rule := lastIgnoreRule(rules, strings.Join(parts[:i], "/"), true)
+		if rule != nil && !rule.IsNegated() {
+			return rule
+		}
+	}
+
+	return lastIgnoreRule(rules, name, dir)
+}
+
+func lastIgnoreRule(rules []*IgnoreRule, name string, dir bool) *IgnoreRule {
+	var match *IgnoreRule
+	for _, rule := range rules {
+		if rule.matches(name, dir) {
+			match = rule
+		}
+	}
+
+	return match
+}
+
+// AppendIgnoreRule adds a rule to the end of a gitignore file,
+// creating the file if needed.
+func AppendIgnoreRule(filename, rule string) error {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	line := strings.TrimSpace(rule) + "\n"
+	if len(contents) > 0 && !bytes.HasSuffix(contents, []byte("\n")) {
+		line = "\n" + line
+	}
+
+	_, err = file.WriteString(line)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,93 @@
+package operations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// IgnoreSuite checks the rule that MatchIgnoreRules finds for each
+// path against the one that "git check-ignore" reports.
+type IgnoreSuite struct {
+	path  string
+	rules []*IgnoreRule
+}
+
+var _ = Suite(&IgnoreSuite{})
+
+var (
+	topIgnore = "*.log\n!keep.log\nbuild/\n!build/keep.log\nreports/**/*.csv\n**/tmp\n/docs/**\n!/docs/keep.txt\ncache/**\n"
+	subIgnore = "# local secrets\nsecret\n/anchored\n"
+)
+
+func (s *IgnoreSuite) SetUpSuite(c *C) {
+	s.path = newTestRepository(c, "a.log", "keep.log", "build/out", "build/keep.log",
+		"reports/a.csv", "reports/2017/01/b.csv", "reports/b.txt", "other/reports/c.csv",
+		"deep/a/tmp", "tmp", "docs/x.txt", "docs/keep.txt", "cache/x", "sub/secret",
+		"sub/dir/secret", "sub/anchored", "sub/dir/anchored", "ok.txt")
+	c.Assert(ioutil.WriteFile(filepath.Join(s.path, ".gitignore"), []byte(topIgnore), 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.path, "sub", ".gitignore"), []byte(subIgnore), 0644), IsNil)
+
+	s.rules = append(ParseIgnoreRules(".gitignore", "", []byte(topIgnore)),
+		ParseIgnoreRules("sub/.gitignore", "sub", []byte(subIgnore))...)
+}
+
+func (s *IgnoreSuite) TestMatchIgnoreRules(c *C) {
+	paths := []string{"a.log", "logs/a.log", "keep.log", "build", "build/out", "build/keep.log",
+		"reports/a.csv", "reports/2017/01/b.csv", "reports/b.txt", "other/reports/c.csv",
+		"deep/a/tmp", "tmp", "docs", "docs/x.txt", "docs/keep.txt", "cache", "cache/x",
+		"sub/secret", "sub/dir/secret", "sub/anchored", "sub/dir/anchored", "ok.txt"}
+
+	// each line is "source:line:pattern<TAB>path", where the first
+	// three fields are empty for paths that no rule matches.
+	lines := git(c, s.path, append([]string{"check-ignore", "--no-index", "--verbose", "--non-matching"}, paths...)...)
+	c.Assert(lines, HasLen, len(paths))
+
+	for idx, line := range lines {
+		fields := strings.SplitN(line, "\t", 2)
+		c.Assert(fields[1], Equals, paths[idx])
+		expected := strings.SplitN(fields[0], ":", 3)
+
+		name := paths[idx]
+		if info, err := os.Stat(filepath.Join(s.path, name)); err == nil && info.IsDir() {
+			name += "/"
+		}
+
+		rule := MatchIgnoreRules(s.rules, name)
+		if expected[2] == "" {
+			c.Check(rule, IsNil, Commentf(paths[idx]))
+			continue
+		}
+
+		c.Assert(rule, NotNil, Commentf(paths[idx]))
+		c.Check(rule.Source, Equals, expected[0], Commentf(paths[idx]))
+		c.Check(strconv.Itoa(rule.Line), Equals, expected[1], Commentf(paths[idx]))
+		c.Check(rule.Pattern, Equals, expected[2], Commentf(paths[idx]))
+	}
+}
+
+func (s *IgnoreSuite) TestDoubleStar(c *C) {
+	rules := ParseIgnoreRules(".gitignore", "", []byte("logs/**/*.log\n**/tmp/\n/out/**\n"))
+
+	for name, matched := range map[string]bool{
+		"logs/a.log":          true,
+		"logs/2017/a.log":     true,
+		"logs/2017/01/a.log":  true,
+		"logs/a.txt":          false,
+		"src/logs/a.log":      false,
+		"tmp/x":               true,
+		"a/b/tmp/x":           true,
+		"a/b/tmp":             false,
+		"out/x":               true,
+		"out/a/b":             true,
+		"out/":                false,
+		"src/out/x":           false,
+		"logs/2017/01/a.log/": true,
+	} {
+		c.Check(MatchIgnoreRules(rules, name) != nil, Equals, matched, Commentf(name))
+	}
+}
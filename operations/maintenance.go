@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"fmt"
+	"time"
+)
+
+// GCMode controls garbage collection during maintenance.
+type GCMode int
+
+const (
+	GCNone GCMode = iota
+	// GCAuto only collects garbage when git's heuristics decide
+	// the repository needs it.
+	GCAuto
+	GCNormal
+	GCAggressive
+)
+
+// DefaultPruneExpire is the age unreachable objects must reach
+// before they are pruned, matching git's gc.pruneExpire default.
+const DefaultPruneExpire = 14 * 24 * time.Hour
+
+// MaintenanceOptions selects the maintenance tasks to run, in the
+// order: gc, repack, prune, commit-graph, fsck. Prune removes
+// unreachable loose objects older than PruneExpire, or older than
+// DefaultPruneExpire when PruneExpire is zero.
+type MaintenanceOptions struct {
+	GC          GCMode
+	Repack      bool
+	Prune       bool
+	PruneExpire time.Time
+	CommitGraph bool
+	Fsck        bool
+}
+
+// PruneBefore returns the time before which unreachable objects
+// are pruned.
+func (o MaintenanceOptions) PruneBefore() time.Time {
+	if o.PruneExpire.IsZero() {
+		return time.Now().Add(-DefaultPruneExpire)
+	}
+
+	return o.PruneExpire
+}
+
+// ObjectStats counts the objects in a repository's object store.
+// Sizes are in bytes.
+type ObjectStats struct {
+	LooseObjects  int
+	LooseSize     int64
+	PackedObjects int
+	Packs         int
+	PackSize      int64
+}
+
+func (s ObjectStats) Objects() int {
+	return s.LooseObjects + s.PackedObjects
+}
+
+func (s ObjectStats) Size() int64 {
+	return s.LooseSize + s.PackSize
+}
+
+// FsckKind classifies a problem reported by fsck.
+type FsckKind int
+
+const (
+	// FsckDangling objects are unreachable and not referenced by
+	// any other object. They are harmless, and removed by prune.
+	FsckDangling FsckKind = iota
+	// FsckMissing objects are referenced but not present.
+	FsckMissing
+	// FsckCorrupt objects are present but cannot be read, or do
+	// not match their ids.
+	FsckCorrupt
+	// FsckError is any other error reported by fsck.
+	FsckError
+)
+
+func (k FsckKind) String() string {
+	switch k {
+	case FsckDangling:
+		return "dangling"
+	case FsckMissing:
+		return "missing"
+	case FsckCorrupt:
+		return "corrupt"
+	default:
+		return "error"
+	}
+}
+
+// FsckFinding is a single problem reported by fsck. ObjectType and
+// Object are empty when fsck did not identify an object.
+type FsckFinding struct {
+	Kind       FsckKind
+	ObjectType string
+	Object     string
+	Message    string
+}
+
+func (f *FsckFinding) String() string {
+	if f.Object == "" {
+		return fmt.Sprintf("%s: %s", f.Kind, f.Message)
+	}
+
+	return fmt.Sprintf("%s %s %s", f.Kind, f.ObjectType, f.Object)
+}
+
+// MaintenanceResult reports the object store before and after
+// maintenance, and the findings of fsck, if it ran.
+type MaintenanceResult struct {
+	Before   ObjectStats
+	After    ObjectStats
+	Findings []*FsckFinding
+}
+
+// IsHealthy returns false if fsck found missing or corrupt objects
+// or other errors. Dangling objects do not affect the result.
+func (r *MaintenanceResult) IsHealthy() bool {
+	for _, finding := range r.Findings {
+		if finding.Kind != FsckDangling {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,36 @@
+package operations
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultNotesRef is the notes ref that git uses when none is given.
+const DefaultNotesRef = "refs/notes/commits"
+
+// ErrNoteNotFound is returned when an object has no note.
+var ErrNoteNotFound = errors.New("no note found for object")
+
+// ExpandNotesRef qualifies the name of a notes ref the way git does,
+// so that "ci" and "notes/ci" both refer to "refs/notes/ci". An empty
+// name refers to the default notes ref.
+func ExpandNotesRef(name string) string {
+	switch {
+	case name == "":
+		return DefaultNotesRef
+	case strings.HasPrefix(name, "refs/notes/"):
+		return name
+	case strings.HasPrefix(name, "notes/"):
+		return "refs/" + name
+	default:
+		return "refs/notes/" + name
+	}
+}
+
+// Note is the note attached to an object. Message has no trailing
+// newline.
+type Note struct {
+	Object  string
+	Note    string
+	Message string
+}
@@ -0,0 +1,42 @@
+package operations
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Patch is a commit formatted as an email message, in the mbox
+// format that "git format-patch" produces and "git am" applies.
+// Subject is the summary of the commit, without the "[PATCH]" prefix.
+type Patch struct {
+	Commit   string
+	Author   string
+	Date     time.Time
+	Subject  string
+	Contents []byte
+}
+
+var patchSubjectPrefix = regexp.MustCompile(`^\[PATCH[^\]]*\]\s*`)
+
+// TrimPatchSubject removes the "[PATCH n/m]" prefix from the subject
+// of a patch email.
+func TrimPatchSubject(subject string) string {
+	return patchSubjectPrefix.ReplaceAllString(strings.TrimSpace(subject), "")
+}
+
+// SplitPatchRange converts a range of commits to format as patches
+// into the revisions to include and exclude. Like "git format-patch",
+// a single revision means the commits since that revision.
+func SplitPatchRange(commits string) (string, string) {
+	if idx := strings.Index(commits, ".."); idx >= 0 {
+		until := commits[idx+2:]
+		if until == "" {
+			until = "HEAD"
+		}
+
+		return until, commits[:idx]
+	}
+
+	return "HEAD", commits
+}
@@ -0,0 +1,61 @@
+package operations
+
+import "fmt"
+
+// ProgressStage identifies the phase of a long running operation
+// that a Progress update describes.
+type ProgressStage int
+
+const (
+	// ProgressRemote describes work done by the remote before
+	// transferring objects, such as counting and compressing.
+	ProgressRemote ProgressStage = iota
+	ProgressReceiving
+	ProgressIndexing
+	ProgressSending
+	ProgressCheckout
+)
+
+func (s ProgressStage) String() string {
+	switch s {
+	case ProgressRemote:
+		return "remote"
+	case ProgressReceiving:
+		return "receiving"
+	case ProgressIndexing:
+		return "indexing"
+	case ProgressSending:
+		return "sending"
+	case ProgressCheckout:
+		return "checkout"
+	default:
+		return fmt.Sprintf("ProgressStage(%d)", int(s))
+	}
+}
+
+// Progress is a single update from a transfer or checkout. Current
+// and Total count objects for transfers, deltas while indexing, and
+// files during checkout.
+type Progress struct {
+	Stage   ProgressStage
+	Current uint64
+	Total   uint64
+	Bytes   uint64
+	Path    string
+}
+
+// ProgressFunc receives progress updates. Implementations call it
+// synchronously from the operation, so it should return quickly.
+type ProgressFunc func(Progress)
+
+// ProgressChannel returns a ProgressFunc that delivers updates to a
+// channel. Updates are dropped rather than blocking the operation
+// when the receiver falls behind.
+func ProgressChannel(updates chan<- Progress) ProgressFunc {
+	return func(p Progress) {
+		select {
+		case updates <- p:
+		default:
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package operations
+
+import "fmt"
+
+// Lease is the value a remote ref must have for a force-with-lease
+// push to overwrite it. An empty Expected value requires that the
+// ref does not exist on the remote.
+type Lease struct {
+	Ref      string
+	Expected string
+}
+
+// PushOptions describe a push to a single remote. Refspecs may be
+// branch or tag names, full ref names, or "src:dst" pairs; when
+// Delete is set, they name the remote refs to delete.
+type PushOptions struct {
+	Remote   string
+	Refspecs []string
+	Force    bool
+	Leases   []Lease
+	Delete   bool
+	Tags     bool
+	Atomic   bool
+}
+
+// PushStatus is the outcome of pushing a single ref.
+type PushStatus int
+
+const (
+	PushUpdated PushStatus = iota
+	PushForced
+	PushCreated
+	PushDeleted
+	PushUpToDate
+	PushRejected
+)
+
+func (s PushStatus) String() string {
+	switch s {
+	case PushUpdated:
+		return "updated"
+	case PushForced:
+		return "forced"
+	case PushCreated:
+		return "created"
+	case PushDeleted:
+		return "deleted"
+	case PushUpToDate:
+		return "up-to-date"
+	case PushRejected:
+		return "rejected"
+	default:
+		return fmt.Sprintf("PushStatus(%d)", int(s))
+	}
+}
+
+// PushUpdate reports the outcome of pushing one local ref to one
+// remote ref. Reason explains rejections.
+type PushUpdate struct {
+	Source      string
+	Destination string
+	Old         string
+	New         string
+	Status      PushStatus
+	Reason      string
+}
+
+// PushResult lists the outcome for every ref in a push.
+type PushResult struct {
+	Updates []*PushUpdate
+}
+
+// Rejected returns the updates that the remote did not accept.
+func (r *PushResult) Rejected() []*PushUpdate {
+	var rejected []*PushUpdate
+	for _, u := range r.Updates {
+		if u.Status == PushRejected {
+			rejected = append(rejected, u)
+		}
+	}
+	return rejected
+}
+
+// Error summarizes the rejected updates, or returns nil if every
+// update was accepted.
+func (r *PushResult) Error() error {
+	rejected := r.Rejected()
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("remote rejected %d ref(s):", len(rejected))
+	for _, u := range rejected {
+		msg += fmt.Sprintf(" %s (%s)", u.Destination, u.Reason)
+	}
+
+	return fmt.Errorf("%s", msg)
+}
@@ -0,0 +1,18 @@
+package operations
+
+import "strings"
+
+// ReflogEntry records a single change to a ref. Old is the all-zero
+// id when the change created the ref.
+type ReflogEntry struct {
+	Old       string
+	New       string
+	Committer Identity
+	Message   string
+}
+
+// IsCreation returns true if the entry records the creation of the
+// ref.
+func (e *ReflogEntry) IsCreation() bool {
+	return strings.Trim(e.Old, "0") == ""
+}
@@ -0,0 +1,8 @@
+package operations
+
+// CommitNode is a commit and the ids of its parents, which describe
+// the shape of history.
+type CommitNode struct {
+	ID      string
+	Parents []string
+}
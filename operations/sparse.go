@@ -0,0 +1,179 @@
+package operations
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// SparseMatcher decides which paths a sparse checkout includes in
+// the working tree.
+//
+// In cone mode, patterns are directories: files at the top level
+// and directly inside the parents of each directory are included,
+// along with everything beneath each directory. Otherwise, patterns
+// follow the syntax of gitignore files. As in git, the last pattern
+// that matches a file decides whether it is included, and files that
+// no pattern matches follow their nearest parent directory that one
+// does.
+type SparseMatcher struct {
+	cone     bool
+	patterns []string
+}
+
+func NewSparseMatcher(cone bool, patterns []string) *SparseMatcher {
+	m := &SparseMatcher{cone: cone}
+	for _, pattern := range patterns {
+		if cone {
+			pattern = strings.Trim(pattern, "/")
+		}
+		if pattern != "" {
+			m.patterns = append(m.patterns, pattern)
+		}
+	}
+
+	return m
+}
+
+// Includes returns true if the file at the given slash-separated
+// path belongs in the working tree.
+func (m *SparseMatcher) Includes(file string) bool {
+	if m.cone {
+		return m.coneIncludes(file)
+	}
+
+	for name, dir := file, false; name != "."; name, dir = path.Dir(name), true {
+		matched, included := false, false
+		for _, pattern := range m.patterns {
+			negated := strings.HasPrefix(pattern, "!")
+			if negated {
+				pattern = pattern[1:]
+			}
+
+			if matchPattern(pattern, name, dir) {
+				matched, included = true, !negated
+			}
+		}
+
+		if matched {
+			return included
+		}
+	}
+
+	return false
+}
+
+func (m *SparseMatcher) coneIncludes(file string) bool {
+	dir := path.Dir(file)
+	if dir == "." {
+		return true
+	}
+
+	for _, pattern := range m.patterns {
+		if dir == pattern || strings.HasPrefix(dir, pattern+"/") || strings.HasPrefix(pattern, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPattern matches a single file or directory against a gitignore
+// style pattern. Patterns ending in a slash only match directories,
+// and patterns without a slash before the end match the last
+// component of the path.
+func matchPattern(pattern, name string, dir bool) bool {
+	if strings.HasSuffix(pattern, "/") {
+		if !dir {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	if !strings.Contains(pattern, "/") {
+		return Wildmatch(pattern, path.Base(name), WildmatchPathname)
+	}
+
+	return Wildmatch(strings.TrimPrefix(pattern, "/"), name, WildmatchPathname)
+}
+
+// FormatConePatterns returns the contents of a sparse-checkout file
+// for the given cone mode directories, in the form git writes.
+func FormatConePatterns(dirs []string) []string {
+	recursive := map[string]bool{}
+	parents := map[string]bool{}
+	for _, dir := range dirs {
+		dir = strings.Trim(dir, "/")
+		if dir == "" {
+			continue
+		}
+
+		recursive[dir] = true
+		for parent := path.Dir(dir); parent != "."; parent = path.Dir(parent) {
+			parents[parent] = true
+		}
+	}
+
+	var names []string
+	for dir := range parents {
+		if !recursive[dir] {
+			names = append(names, dir)
+		}
+	}
+	for dir := range recursive {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+
+	lines := []string{"/*", "!/*/"}
+	for _, dir := range names {
+		if isCoveredDir(recursive, dir) {
+			continue
+		}
+
+		lines = append(lines, "/"+dir+"/")
+		if !recursive[dir] {
+			lines = append(lines, "!/"+dir+"/*/")
+		}
+	}
+
+	return lines
+}
+
+// isCoveredDir returns true if one of the recursive directories
+// already contains dir.
+func isCoveredDir(recursive map[string]bool, dir string) bool {
+	for parent := path.Dir(dir); parent != "."; parent = path.Dir(parent) {
+		if recursive[parent] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseConePatterns returns the directories in a cone mode
+// sparse-checkout file, in the form that "git sparse-checkout list"
+// prints them.
+func ParseConePatterns(lines []string) []string {
+	negated := map[string]bool{}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "!/") && strings.HasSuffix(line, "/*/") {
+			negated[strings.TrimSuffix(line[1:], "*/")] = true
+		}
+	}
+
+	var dirs []string
+	for _, line := range lines {
+		if line == "/*" || !strings.HasPrefix(line, "/") || !strings.HasSuffix(line, "/") {
+			continue
+		}
+		if negated[line] {
+			continue
+		}
+
+		dirs = append(dirs, strings.Trim(line, "/"))
+	}
+
+	return dirs
+}
@@ -0,0 +1,91 @@
+package operations
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+// SparseSuite checks the sparse checkout helpers against the patterns
+// that git writes, and the files that it checks out.
+type SparseSuite struct {
+	path string
+}
+
+var _ = Suite(&SparseSuite{})
+
+func (s *SparseSuite) SetUpSuite(c *C) {
+	s.path = newTestRepository(c, "README", "a/x", "a/b/y", "a/b/c/w", "c/z",
+		"logs/app.log", "logs/2017/01/app.log", "src/main.go", "vendor/lib.go")
+}
+
+// patterns returns the lines of the sparse-checkout file.
+func (s *SparseSuite) patterns(c *C) []string {
+	data, err := ioutil.ReadFile(filepath.Join(s.path, ".git", "info", "sparse-checkout"))
+	c.Assert(err, IsNil)
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// checkIncludes compares the matcher with the files that git checked
+// out, which "ls-files -t" reports as "H", and those that it skipped,
+// which it reports as "S".
+func (s *SparseSuite) checkIncludes(c *C, matcher *SparseMatcher, patterns []string) {
+	for _, line := range git(c, s.path, "ls-files", "-t") {
+		fn := line[2:]
+		c.Check(matcher.Includes(fn), Equals, line[0] == 'H', Commentf("%s with %q", fn, patterns))
+	}
+}
+
+func (s *SparseSuite) TestConePatterns(c *C) {
+	for _, dirs := range [][]string{
+		nil,
+		{"a"},
+		{"a/b"},
+		{"a/b", "c"},
+		{"a/b/c"},
+		{"a", "a/b/c"},
+		{"logs/2017", "src"},
+	} {
+		comment := Commentf("%q", dirs)
+		git(c, s.path, append([]string{"sparse-checkout", "set", "--cone"}, dirs...)...)
+
+		lines := s.patterns(c)
+		c.Check(FormatConePatterns(dirs), DeepEquals, lines, comment)
+		c.Check(ParseConePatterns(lines), DeepEquals, git(c, s.path, "sparse-checkout", "list"), comment)
+		s.checkIncludes(c, NewSparseMatcher(true, ParseConePatterns(lines)), dirs)
+	}
+}
+
+func (s *SparseSuite) TestPatterns(c *C) {
+	for _, patterns := range [][]string{
+		{"/*", "!/*/", "/a/", "!/a/b/"},
+		{"*.go", "!vendor/"},
+		{"*.go", "!vendor/**"},
+		{"README", "c/"},
+		{"a/", "!b/"},
+		{"logs/", "!2017/"},
+		{"/a/b"},
+		{"logs/**/*.log"},
+		{"**/b"},
+		{"/a/**", "!a/b/**"},
+		{"**/*.log", "!logs/2017/**"},
+	} {
+		comment := Commentf("%q", patterns)
+		git(c, s.path, append([]string{"sparse-checkout", "set", "--no-cone"}, patterns...)...)
+
+		c.Check(s.patterns(c), DeepEquals, patterns, comment)
+		s.checkIncludes(c, NewSparseMatcher(false, patterns), patterns)
+	}
+}
@@ -0,0 +1,47 @@
+// Package operations holds the option and result types shared by
+// the Repository implementations, so that the "wrapped" and "direct"
+// backends can return the same values without depending on the
+// top-level gitgone package.
+package operations
+
+// SubmoduleStatus is a bit field that describes the state of a
+// submodule's working tree relative to the superproject.
+type SubmoduleStatus int
+
+const (
+	// SubmoduleInitialized is set when the submodule has been
+	// registered in the superproject's configuration and has a
+	// working tree.
+	SubmoduleInitialized SubmoduleStatus = 1 << iota
+
+	// SubmoduleOutOfDate is set when the commit checked out in
+	// the submodule differs from the commit recorded in the
+	// superproject.
+	SubmoduleOutOfDate
+
+	// SubmoduleDirty is set when the submodule's working tree or
+	// index has modifications of its own.
+	SubmoduleDirty
+)
+
+// Submodule describes a single submodule of a repository.
+type Submodule struct {
+	Name     string
+	Path     string
+	URL      string
+	Recorded string
+	Checkout string
+	Status   SubmoduleStatus
+}
+
+func (s *Submodule) IsInitialized() bool {
+	return s.Status&SubmoduleInitialized != 0
+}
+
+func (s *Submodule) IsOutOfDate() bool {
+	return s.Status&SubmoduleOutOfDate != 0
+}
+
+func (s *Submodule) IsDirty() bool {
+	return s.Status&SubmoduleDirty != 0
+}
@@ -0,0 +1,296 @@
+package operations
+
+import "strings"
+
+// WildmatchFlags change how Wildmatch compares a path with a
+// pattern.
+type WildmatchFlags int
+
+const (
+	// WildmatchPathname stops "*", "?" and character classes from
+	// matching "/", so that only "**" between slashes matches
+	// across directories, as in gitignore and gitattributes files.
+	WildmatchPathname WildmatchFlags = 1 << iota
+
+	// WildmatchCaseFold ignores the case of letters.
+	WildmatchCaseFold
+)
+
+// Wildmatch matches a path against a glob pattern using the rules of
+// git's wildmatch, which git uses for gitignore and gitattributes
+// patterns, pathspecs, tag name patterns and the conditions of config
+// includes. Patterns support "*", "?", "**", backslash escapes and
+// bracket expressions, including negation and POSIX character
+// classes.
+func Wildmatch(pattern, name string, flags WildmatchFlags) bool {
+	return dowild(pattern, name, flags) == wildMatch
+}
+
+// the results of dowild, where the abort values stop the callers from
+// trying to match the rest of the text at later positions.
+const (
+	wildMatch = iota
+	wildNoMatch
+	wildAbortAll
+	wildAbortToStarStar
+)
+
+// dowild is a port of the function of the same name in git's
+// wildmatch.c, and follows its structure closely.
+func dowild(p, text string, flags WildmatchFlags) int {
+	fold := flags&WildmatchCaseFold != 0
+	pathname := flags&WildmatchPathname != 0
+
+	pi, ti := 0, 0
+	for ; pi < len(p); pi, ti = pi+1, ti+1 {
+		pch := p[pi]
+		tch := byteAt(text, ti)
+		if ti >= len(text) && pch != '*' {
+			return wildAbortAll
+		}
+		if fold {
+			tch, pch = toLower(tch), toLower(pch)
+		}
+
+		switch pch {
+		case '\\':
+			// match the following character literally
+			pi++
+			if tch != byteAt(p, pi) {
+				return wildNoMatch
+			}
+		default:
+			if tch != pch {
+				return wildNoMatch
+			}
+		case '?':
+			if pathname && tch == '/' {
+				return wildNoMatch
+			}
+		case '*':
+			var matchSlash bool
+			if pi++; byteAt(p, pi) == '*' {
+				prev := pi - 2
+				for pi++; byteAt(p, pi) == '*'; pi++ {
+				}
+
+				switch {
+				case !pathname:
+					matchSlash = true
+				case (prev < 0 || p[prev] == '/') &&
+					(pi == len(p) || p[pi] == '/' || (p[pi] == '\\' && byteAt(p, pi+1) == '/')):
+					// "**/" may match no directories at all,
+					// so that "a/**/b" matches "a/b".
+					if byteAt(p, pi) == '/' && dowild(p[pi+1:], text[ti:], flags) == wildMatch {
+						return wildMatch
+					}
+					matchSlash = true
+				default:
+					matchSlash = false
+				}
+			} else {
+				matchSlash = !pathname
+			}
+
+			if pi == len(p) {
+				// a trailing "**" matches everything, and a
+				// trailing "*" only matches within a directory.
+				if !matchSlash && strings.IndexByte(text[ti:], '/') >= 0 {
+					return wildNoMatch
+				}
+				return wildMatch
+			} else if !matchSlash && p[pi] == '/' {
+				// "*/" matches the rest of this directory.
+				slash := strings.IndexByte(text[ti:], '/')
+				if slash < 0 {
+					return wildNoMatch
+				}
+				ti += slash
+				continue
+			}
+
+			for ti < len(text) {
+				// skip ahead to the next occurrence of a literal
+				// that follows the star.
+				if !isGlobSpecial(p[pi]) {
+					lit := p[pi]
+					if fold {
+						lit = toLower(lit)
+					}
+					for ; ti < len(text) && (matchSlash || text[ti] != '/'); ti++ {
+						if c := text[ti]; c == lit || (fold && toLower(c) == lit) {
+							break
+						}
+					}
+					if ti == len(text) || (text[ti] != lit && !(fold && toLower(text[ti]) == lit)) {
+						return wildNoMatch
+					}
+				}
+
+				if matched := dowild(p[pi:], text[ti:], flags); matched != wildNoMatch {
+					if !matchSlash || matched != wildAbortToStarStar {
+						return matched
+					}
+				} else if !matchSlash && text[ti] == '/' {
+					return wildAbortToStarStar
+				}
+				ti++
+			}
+			return wildAbortAll
+		case '[':
+			pi++
+			pch = byteAt(p, pi)
+			if pch == '^' {
+				pch = '!'
+			}
+			negated := pch == '!'
+			if negated {
+				pi++
+			}
+
+			var prev byte
+			matched := false
+			for {
+				if pi >= len(p) {
+					return wildAbortAll
+				}
+				pch = p[pi]
+
+				switch {
+				case pch == '\\':
+					pi++
+					if pi >= len(p) {
+						return wildAbortAll
+					}
+					pch = p[pi]
+					if tch == pch {
+						matched = true
+					}
+				case pch == '-' && prev != 0 && pi+1 < len(p) && p[pi+1] != ']':
+					pi++
+					pch = p[pi]
+					if pch == '\\' {
+						pi++
+						if pi >= len(p) {
+							return wildAbortAll
+						}
+						pch = p[pi]
+					}
+					if tch <= pch && tch >= prev {
+						matched = true
+					} else if fold && isLower(tch) {
+						if upper := toUpper(tch); upper <= pch && upper >= prev {
+							matched = true
+						}
+					}
+					pch = 0 // a range cannot start another range
+				case pch == '[' && byteAt(p, pi+1) == ':':
+					start := pi + 2
+					end := strings.IndexByte(p[start:], ']')
+					if end < 0 {
+						return wildAbortAll
+					}
+					end += start
+
+					if end-start < 1 || p[end-1] != ':' {
+						// without ":]", "[" is an ordinary member
+						if tch == '[' {
+							matched = true
+						}
+						break
+					}
+
+					ok, known := matchCharClass(p[start:end-1], tch, fold)
+					if !known {
+						return wildAbortAll
+					}
+					if ok {
+						matched = true
+					}
+					pi = end
+					pch = 0
+				default:
+					if tch == pch {
+						matched = true
+					}
+				}
+
+				prev = pch
+				if pi++; byteAt(p, pi) == ']' {
+					break
+				}
+			}
+
+			if matched == negated || (pathname && tch == '/') {
+				return wildNoMatch
+			}
+		}
+	}
+
+	if ti < len(text) {
+		return wildNoMatch
+	}
+	return wildMatch
+}
+
+// matchCharClass reports whether a character belongs to a POSIX
+// character class, such as "alpha", and whether the class is known.
+func matchCharClass(class string, c byte, fold bool) (bool, bool) {
+	switch class {
+	case "alnum":
+		return isLower(c) || isUpper(c) || isDigit(c), true
+	case "alpha":
+		return isLower(c) || isUpper(c), true
+	case "blank":
+		return c == ' ' || c == '\t', true
+	case "cntrl":
+		return c < ' ' || c == 0x7f, true
+	case "digit":
+		return isDigit(c), true
+	case "graph":
+		return c > ' ' && c < 0x7f, true
+	case "lower":
+		return isLower(c), true
+	case "print":
+		return c >= ' ' && c < 0x7f, true
+	case "punct":
+		return c > ' ' && c < 0x7f && !isLower(c) && !isUpper(c) && !isDigit(c), true
+	case "space":
+		return c == ' ' || (c >= '\t' && c <= '\r'), true
+	case "upper":
+		return isUpper(c) || (fold && isLower(c)), true
+	case "xdigit":
+		return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'), true
+	default:
+		return false, false
+	}
+}
+
+func byteAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return 0
+}
+
+func isGlobSpecial(c byte) bool {
+	return c == '*' || c == '?' || c == '[' || c == '\\'
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isLower(c byte) bool { return c >= 'a' && c <= 'z' }
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
+
+func toLower(c byte) byte {
+	if isUpper(c) {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func toUpper(c byte) byte {
+	if isLower(c) {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,91 @@
+package operations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type WildmatchSuite struct{}
+
+var _ = Suite(&WildmatchSuite{})
+
+// wildmatchCases give the expected results without flags, with
+// WildmatchPathname, and with WildmatchPathname and WildmatchCaseFold.
+var wildmatchCases = []struct {
+	pattern  string
+	text     string
+	plain    bool
+	pathname bool
+	folded   bool
+}{
+	{"foo", "foo", true, true, true},
+	{"bar", "foo", false, false, false},
+	{"???", "foo", true, true, true},
+	{"??", "foo", false, false, false},
+	{"*", "foo", true, true, true},
+	{"f*", "foo", true, true, true},
+	{"*f", "foo", false, false, false},
+	{"*ob*a*r*", "foobar", true, true, true},
+	{"*ab", "aaaaaaabababab", true, true, true},
+	{`foo\*`, "foo*", true, true, true},
+	{"[ab]", "a", true, true, true},
+	{"[!a-c]x", "dx", true, true, true},
+	{"[^a-c]x", "bx", false, false, false},
+	{"[a-]", "-", true, true, true},
+	{"[[:digit:]]*", "5abc", true, true, true},
+	{"[[:alpha:]][[:alnum:]]", "a1", true, true, true},
+	{"[[:upper:]]", "a", false, false, true},
+	{"[A-C]x", "bx", false, false, true},
+	{"FOO", "foo", false, false, true},
+	{"*", "a/b", true, false, false},
+	{"*.c", "src/a.c", true, false, false},
+	{"a?b", "a/b", true, false, false},
+	{"a[/]b", "a/b", true, false, false},
+	{"foo*", "foo/bar", true, false, false},
+	{"*/foo", "a/b/foo", true, false, false},
+	{"foo/*/bar", "foo/a/b/bar", true, false, false},
+	{"foo**bar", "foo/x/bar", true, false, false},
+	{"**", "a/b/c", true, true, true},
+	{"**/foo", "foo", false, true, true},
+	{"**/foo", "x/y/foo", true, true, true},
+	{"foo/**", "foo/a/b", true, true, true},
+	{"foo/**/bar", "foo/bar", false, true, true},
+	{"foo/**/bar", "foo/a/b/bar", true, true, true},
+	{"docs/**/*.md", "docs/a.md", false, true, true},
+	{"docs/**/*.md", "docs/x/y/a.md", true, true, true},
+	{"src/**/gen/*", "src/gen/a", false, true, true},
+	{"src/**/gen/*", "src/x/gen/a/b", true, false, false},
+}
+
+func (s *WildmatchSuite) TestWildmatch(c *C) {
+	for _, test := range wildmatchCases {
+		comment := Commentf("%q against %q", test.pattern, test.text)
+		c.Check(Wildmatch(test.pattern, test.text, 0), Equals, test.plain, comment)
+		c.Check(Wildmatch(test.pattern, test.text, WildmatchPathname), Equals, test.pathname, comment)
+		c.Check(Wildmatch(test.pattern, test.text, WildmatchPathname|WildmatchCaseFold), Equals, test.folded, comment)
+	}
+}
+
+// TestAgainstGit checks the expected results with git, which matches
+// pathspecs without flags, and ":(glob)" pathspecs with
+// WildmatchPathname.
+func (s *WildmatchSuite) TestAgainstGit(c *C) {
+	for _, test := range wildmatchCases {
+		path := newTestRepository(c)
+		fn := filepath.Join(path, filepath.FromSlash(test.text))
+		c.Assert(os.MkdirAll(filepath.Dir(fn), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fn, nil, 0644), IsNil)
+
+		for spec, expected := range map[string]bool{
+			test.pattern:                   test.plain,
+			":(glob)" + test.pattern:       test.pathname,
+			":(glob,icase)" + test.pattern: test.folded,
+		} {
+			matched := git(c, path, "ls-files", "--others", "--", spec)
+			c.Check(len(matched) == 1, Equals, expected, Commentf("%q against %q", spec, test.text))
+		}
+	}
+}
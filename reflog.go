@@ -0,0 +1,54 @@
+package gitgone
+
+import (
+	"fmt"
+
+	"github.com/tychoish/gitgone/operations"
+)
+
+// Undo moves a ref back to the value it had the given number of
+// changes ago, as recorded by ref@{steps} in its reflog, and returns
+// that reflog entry. Undo fails if the ref has changed since its
+// most recent reflog entry.
+//
+// When the ref is HEAD or the branch checked out in the working tree,
+// the index and working tree are reset to match, discarding
+// uncommitted changes like "git reset --hard".
+func (self *RepositoryManager) Undo(ref string, steps int) (*operations.ReflogEntry, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("cannot undo %d changes to %s", steps, ref)
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	entries, err := self.Reflog(ref)
+	if err != nil {
+		return nil, err
+	}
+	if steps >= len(entries) {
+		return nil, fmt.Errorf("cannot undo %d changes to %s, the reflog has %d entries",
+			steps, ref, len(entries))
+	}
+
+	name := ref
+	branch := self.Branch()
+	checkedOut := ref == "HEAD" || (branch != "" && (ref == branch || ref == "refs/heads/"+branch))
+	if checkedOut && branch != "" {
+		name = "refs/heads/" + branch
+	}
+
+	entry := entries[steps]
+	message := fmt.Sprintf("undo: moving to %s@{%d}", ref, steps)
+	if err = self.UpdateRef(name, entry.New, entries[0].New, message); err != nil {
+		return nil, err
+	}
+
+	if checkedOut && !self.IsBare() {
+		if err = self.Reset("HEAD", true); err != nil {
+			return entry, err
+		}
+	}
+
+	return entry, nil
+}
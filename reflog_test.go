@@ -0,0 +1,104 @@
+package gitgone
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ReflogSuite struct {
+	repo *RepositoryManager
+}
+
+var _ = Suite(&ReflogSuite{})
+
+func (s *ReflogSuite) SetUpTest(c *C) {
+	s.repo = newTestRepository(c, false)
+
+	for _, content := range []string{"one", "two", "three"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(s.repo.Path(), "file"), []byte(content+"\n"), 0644), IsNil)
+		c.Assert(s.repo.Stage("file"), IsNil)
+		c.Assert(s.repo.Commit(content), IsNil)
+	}
+}
+
+func (s *ReflogSuite) TestReflog(c *C) {
+	entries, err := s.repo.Reflog("master")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+	c.Assert(entries[0].New, Equals, git(c, s.repo, "rev-parse", "master"))
+	c.Assert(entries[0].Old, Equals, entries[1].New)
+	c.Assert(entries[0].Message, Equals, "commit: three")
+	c.Assert(entries[0].Committer.Name, Equals, "Gitgone")
+	c.Assert(entries[0].Committer.Email, Equals, "gitgone@example.com")
+	c.Assert(time.Since(entries[0].Committer.When) < time.Hour, Equals, true)
+	c.Assert(entries[2].IsCreation(), Equals, true)
+	c.Assert(entries[1].IsCreation(), Equals, false)
+
+	c.Assert(s.repo.ReflogExpire("master", time.Now().Add(-time.Hour)), IsNil)
+	entries, err = s.repo.Reflog("master")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+
+	c.Assert(s.repo.ReflogExpire("", time.Now().Add(time.Hour)), IsNil)
+	entries, err = s.repo.Reflog("HEAD")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 0)
+}
+
+func (s *ReflogSuite) TestUndo(c *C) {
+	second := git(c, s.repo, "rev-parse", "master~1")
+
+	_, err := s.repo.Undo("master", 3)
+	c.Assert(err, NotNil)
+
+	// a bad reset that automation wants to recover from
+	c.Assert(s.repo.Reset("HEAD~2", true), IsNil)
+
+	entry, err := s.repo.Undo("master", 1)
+	c.Assert(err, IsNil)
+	c.Assert(entry.Message, Equals, "commit: three")
+	c.Assert(git(c, s.repo, "log", "-1", "--format=%s", "master"), Equals, "three")
+
+	contents, err := ioutil.ReadFile(filepath.Join(s.repo.Path(), "file"))
+	c.Assert(err, IsNil)
+	c.Assert(string(contents), Equals, "three\n")
+
+	entries, err := s.repo.Reflog("master")
+	c.Assert(err, IsNil)
+	c.Assert(entries[0].Message, Equals, "undo: moving to master@{1}")
+
+	c.Assert(s.repo.CreateBranch("feature", "HEAD"), IsNil)
+	c.Assert(s.repo.UpdateRef("refs/heads/feature", second, "", "moving feature"), IsNil)
+	c.Assert(s.repo.UpdateRef("refs/heads/feature", second, "0000000", "conflict"), NotNil)
+
+	entry, err = s.repo.Undo("feature", 1)
+	c.Assert(err, IsNil)
+	c.Assert(git(c, s.repo, "rev-parse", "feature"), Equals, git(c, s.repo, "rev-parse", "master"))
+	c.Assert(git(c, s.repo, "symbolic-ref", "--short", "HEAD"), Equals, "master")
+}
+
+func (s *ReflogSuite) TestUndoDetached(c *C) {
+	master := git(c, s.repo, "rev-parse", "master")
+	git(c, s.repo, "checkout", "--quiet", "--detach")
+	c.Assert(s.repo.Branch(), Equals, "")
+
+	c.Assert(s.repo.Reset("HEAD~2", true), IsNil)
+
+	entry, err := s.repo.Undo("HEAD", 1)
+	c.Assert(err, IsNil)
+	c.Assert(entry.New, Equals, master)
+	c.Assert(git(c, s.repo, "rev-parse", "HEAD"), Equals, master)
+	c.Assert(s.repo.Branch(), Equals, "")
+
+	contents, err := ioutil.ReadFile(filepath.Join(s.repo.Path(), "file"))
+	c.Assert(err, IsNil)
+	c.Assert(string(contents), Equals, "three\n")
+
+	// the branch that was checked out before detaching is untouched
+	c.Assert(git(c, s.repo, "rev-parse", "master"), Equals, master)
+	c.Assert(s.repo.Reset("HEAD~1", true), IsNil)
+	c.Assert(git(c, s.repo, "rev-parse", "master"), Equals, master)
+}
@@ -12,10 +12,17 @@ package gitgone
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
+	"github.com/tychoish/gitgone/config"
+	"github.com/tychoish/gitgone/credentials"
 	"github.com/tychoish/gitgone/gitrect"
 	"github.com/tychoish/gitgone/gitwrap"
+	"github.com/tychoish/gitgone/hooks"
+	"github.com/tychoish/gitgone/operations"
+	"github.com/tychoish/gitgone/signing"
 )
 
 // The Repository interface provides an abstract, high-level set of
@@ -29,12 +36,15 @@ import (
 // perform on a git repository during normal development operations.
 type Repository interface {
 	Path() string
+	Config() config.Config
 	Branch() string
 	BranchExists(string) bool
 	IsBare() bool
 	IsExists() bool
 
+	Init(bool, string, string) error
 	Clone(string, string) error
+	CloneWithOptions(string, operations.CloneOptions) error
 	Checkout(string) error
 
 	CreateBranch(string, string) error
@@ -45,22 +55,74 @@ type Repository interface {
 	Reset(string, bool) error
 	CherryPick(...string) error
 
+	SetCredentials(credentials.Provider)
+	SetProgress(operations.ProgressFunc)
+	SetSkipHooks(bool)
 	Fetch(string) error
+	FetchWithOptions(operations.FetchOptions) (*operations.FetchResult, error)
+	FetchFromBundle(string, ...string) (*operations.FetchResult, error)
+	CreateBundle(io.Writer, ...string) error
+	VerifyBundle(string) (*operations.Bundle, error)
+	Deepen(int) error
+	Unshallow() error
 	Pull(string, string) error
 	PullRebase(string, string) error
 	Push(string, string) error
+	PushWithOptions(operations.PushOptions) (*operations.PushResult, error)
 
 	CreateTag(string, string, string, bool) error
 	DeleteTag(string) error
 	IsTagged(string, string, bool) bool
+	Describe(string, operations.DescribeOptions) (*operations.Description, error)
+	VerifyTag(string) (*signing.Verification, error)
+	SetSigner(signing.Signer)
+	SetVerifier(signing.Verifier)
 
 	Stage(...string) error
 	StageAllPath(string)
+	IsIgnored(...string) ([]*operations.IgnoreResult, error)
+	AddIgnoreRule(string, bool) error
+	Attributes(string, ...string) ([]*operations.Attribute, error)
+	Clean(operations.CleanOptions) ([]string, error)
 
 	Commit(string) error
 	CommitAll(string) error
 	Amend(string) error
 	AmendAll(string) error
+	VerifyCommit(string) (*signing.Verification, error)
+	WriteCommit(operations.CommitRequest) (string, error)
+
+	ResolveCommit(string) (string, error)
+	RevList([]string, []string) ([]*operations.CommitNode, error)
+	UpdateRef(string, string, string, string) error
+	Reflog(string) ([]*operations.ReflogEntry, error)
+	ReflogExpire(string, time.Time) error
+	Blame(string, string, operations.BlameOptions) (*operations.BlameResult, error)
+	Grep(string, string, operations.GrepOptions) ([]*operations.GrepMatch, error)
+	Archive(string, operations.ArchiveFormat, string, io.Writer) error
+
+	FormatPatch(string) ([]*operations.Patch, error)
+	ApplyPatch(io.Reader, bool, bool, bool) error
+	ApplyMailbox(io.Reader) error
+
+	AddNote(string, string, string, bool) error
+	ReadNote(string, string) (string, error)
+	RemoveNote(string, string) error
+	ListNotes(string) ([]*operations.Note, error)
+
+	Maintenance(operations.MaintenanceOptions) (*operations.MaintenanceResult, error)
+
+	Submodules() ([]*operations.Submodule, error)
+	SubmoduleInit(...string) error
+	SubmoduleUpdate(bool, ...string) error
+	SubmoduleSync(bool) error
+	SetRecurseSubmodules(bool)
+
+	SparseCheckoutInit(bool) error
+	SparseCheckoutSet(...string) error
+	SparseCheckoutAdd(...string) error
+	SparseCheckoutList() ([]string, error)
+	SparseCheckoutDisable() error
 }
 
 // RepositoryManger embeds a Repository interface and provides acces
@@ -68,6 +130,8 @@ type Repository interface {
 // provided by the interface.
 type RepositoryManager struct {
 	Repository
+
+	hooks map[hooks.Type][]hooks.Func
 }
 
 // Constructor for a RepositoryManager backed by an implementation
@@ -77,7 +141,7 @@ type RepositoryManager struct {
 // repositories that you normally interact with using the "git"
 // binary.
 func NewWrappedRepository(path string) *RepositoryManager {
-	return &RepositoryManager{gitwrap.NewRepository(path)}
+	return &RepositoryManager{Repository: gitwrap.NewRepository(path)}
 }
 
 // Constructor for a RepositoryManager backed by an implementation
@@ -87,13 +151,130 @@ func NewWrappedRepository(path string) *RepositoryManager {
 // differ somewhat, particularly for more proficient users. The direct
 // operations are likely much more performant.
 func NewDirectRepository(path string) *RepositoryManager {
-	return &RepositoryManager{gitrect.NewRepository(path)}
+	return &RepositoryManager{Repository: gitrect.NewRepository(path)}
+}
+
+// EnsureRepository initializes a new repository, or if a repository
+// already exists, checks that it has the requested layout. The
+// initial branch and template only apply to new repositories.
+func (self *RepositoryManager) EnsureRepository(bare bool, initialBranch, template string) error {
+	if !self.IsExists() {
+		return self.Init(bare, initialBranch, template)
+	}
+
+	if self.IsBare() != bare {
+		if bare {
+			return fmt.Errorf("repository at %s exists but is not bare", self.Path())
+		}
+		return fmt.Errorf("repository at %s exists but is bare", self.Path())
+	}
+
+	return nil
 }
 
 func (self *RepositoryManager) CloneMaster(remote string) error {
 	return self.Clone(remote, "master")
 }
 
+// CloneRecursive clones the branch from the remote and then
+// initializes and updates all submodules, recursively.
+func (self *RepositoryManager) CloneRecursive(remote, branch string) error {
+	self.SetRecurseSubmodules(true)
+	return self.Clone(remote, branch)
+}
+
+// UpdateSubmodules initializes any submodules that have not yet been
+// initialized and then updates all submodules, recursively, to the
+// commits recorded in the repository.
+func (self *RepositoryManager) UpdateSubmodules() error {
+	err := self.SubmoduleInit()
+	if err != nil {
+		return err
+	}
+
+	return self.SubmoduleUpdate(true)
+}
+
+// SetIdentity sets the author and committer identity used for
+// commits and tags in this repository, in the local configuration,
+// so that operations do not depend on the global git configuration.
+func (self *RepositoryManager) SetIdentity(name, email string) error {
+	conf := self.Config()
+
+	err := conf.Set(config.Local, "user.name", name)
+	if err != nil {
+		return err
+	}
+
+	return conf.Set(config.Local, "user.email", email)
+}
+
+// CloneShallow clones only the most recent commit of a single branch
+// from the remote.
+func (self *RepositoryManager) CloneShallow(remote, branch string) error {
+	return self.CloneWithOptions(remote, operations.CloneOptions{
+		Branch:       branch,
+		Depth:        1,
+		SingleBranch: true,
+	})
+}
+
+// FetchPrune fetches from the remote, removing any remote-tracking
+// refs that no longer exist on the remote, and returns the refs that
+// changed.
+func (self *RepositoryManager) FetchPrune(remote string) (*operations.FetchResult, error) {
+	return self.FetchWithOptions(operations.FetchOptions{Remote: remote, Prune: true})
+}
+
+// PushNotes pushes a notes ref, which git does not push by default,
+// to the same ref on the remote.
+func (self *RepositoryManager) PushNotes(remote, ref string) (*operations.PushResult, error) {
+	ref = operations.ExpandNotesRef(ref)
+
+	return self.PushWithOptions(operations.PushOptions{
+		Remote:   remote,
+		Refspecs: []string{ref + ":" + ref},
+	})
+}
+
+// FetchNotes fetches a notes ref, which git does not fetch by
+// default, from the remote into the same local ref. The fetch fails
+// rather than discard local notes that are not on the remote.
+func (self *RepositoryManager) FetchNotes(remote, ref string) (*operations.FetchResult, error) {
+	ref = operations.ExpandNotesRef(ref)
+
+	return self.FetchWithOptions(operations.FetchOptions{
+		Remote:   remote,
+		Refspecs: []string{ref + ":" + ref},
+	})
+}
+
+// SemanticVersion derives the version of the working tree from the
+// nearest release tag, such as "v1.2.0" or "1.2.0-rc.1". Commits
+// since the tag, and uncommitted changes, are recorded in the
+// version.
+func (self *RepositoryManager) SemanticVersion() (*operations.Version, error) {
+	desc, err := self.Describe("HEAD", operations.DescribeOptions{
+		Tags:  true,
+		Match: []string{"v[0-9]*.[0-9]*.[0-9]*", "[0-9]*.[0-9]*.[0-9]*"},
+		Dirty: !self.IsBare(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := operations.ParseVersion(desc.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	version.Distance = desc.Distance
+	version.Commit = desc.Commit
+	version.Dirty = desc.Dirty
+
+	return version, nil
+}
+
 func (self *RepositoryManager) ResetHeadHard() error {
 	return self.Reset("HEAD", true)
 }
@@ -104,7 +285,7 @@ func (self *RepositoryManager) ResetHead() error {
 
 func (self *RepositoryManager) CheckoutBranch(branch, starting string) error {
 	if self.IsBare() {
-		return fmt.Errorf("cannot checkout new branch on a bare repository", branch)
+		return fmt.Errorf("cannot checkout new branch %s on a bare repository", branch)
 	}
 	if !self.IsExists() {
 		return fmt.Errorf("no repository exists at %s", self.Path())
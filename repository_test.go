@@ -0,0 +1,41 @@
+package gitgone
+
+import (
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type RepositorySuite struct{}
+
+var _ = Suite(&RepositorySuite{})
+
+func (s *RepositorySuite) TestEnsureRepository(c *C) {
+	path := filepath.Join(c.MkDir(), "repo")
+
+	repo := NewWrappedRepository(path)
+	c.Assert(repo.IsExists(), Equals, false)
+	c.Assert(repo.EnsureRepository(false, "trunk", ""), IsNil)
+	c.Assert(repo.IsExists(), Equals, true)
+	c.Assert(repo.IsBare(), Equals, false)
+	c.Assert(repo.Branch(), Equals, "trunk")
+
+	c.Logf("ensuring an existing repository is a no-op, and ignores the initial branch")
+	repo = NewWrappedRepository(path)
+	c.Assert(repo.EnsureRepository(false, "other", ""), IsNil)
+	c.Assert(repo.Branch(), Equals, "trunk")
+
+	c.Assert(repo.EnsureRepository(true, "", ""), ErrorMatches, ".* exists but is not bare")
+}
+
+func (s *RepositorySuite) TestEnsureBareRepository(c *C) {
+	path := filepath.Join(c.MkDir(), "repo.git")
+
+	repo := NewWrappedRepository(path)
+	c.Assert(repo.EnsureRepository(true, "", ""), IsNil)
+	c.Assert(repo.IsBare(), Equals, true)
+
+	repo = NewWrappedRepository(path)
+	c.Assert(repo.EnsureRepository(true, "", ""), IsNil)
+	c.Assert(repo.EnsureRepository(false, "", ""), ErrorMatches, ".* exists but is bare")
+}
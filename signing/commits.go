@@ -0,0 +1,99 @@
+package signing
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Commit describes a commit object.
+type Commit struct {
+	Tree      string
+	Parents   []string
+	Author    string
+	Committer string
+	Message   string
+}
+
+func (c *Commit) header() []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "tree %s\n", c.Tree)
+	for _, parent := range c.Parents {
+		fmt.Fprintf(buf, "parent %s\n", parent)
+	}
+	fmt.Fprintf(buf, "author %s\n", c.Author)
+	fmt.Fprintf(buf, "committer %s\n", c.Committer)
+
+	return buf.Bytes()
+}
+
+func (c *Commit) body() []byte {
+	message := c.Message
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	return []byte("\n" + message)
+}
+
+// Payload returns the content of the commit object without a
+// signature, which is the data that is signed.
+func (c *Commit) Payload() []byte {
+	return append(c.header(), c.body()...)
+}
+
+// SignCommit returns the content of a signed commit object, in which
+// the signature is stored in the gpgsig header.
+func SignCommit(commit *Commit, signer Signer) ([]byte, error) {
+	signature, err := signer.Sign(commit.Payload())
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.TrimRight(string(signature), "\n")
+	data := commit.header()
+	data = append(data, "gpgsig "+strings.Replace(lines, "\n", "\n ", -1)+"\n"...)
+
+	return append(data, commit.body()...), nil
+}
+
+// SplitCommit separates the content of a commit object into the
+// signed payload and the signature. The signature is nil for unsigned
+// commits.
+func SplitCommit(data []byte) ([]byte, []byte) {
+	// the headers end with the newline before the blank line, or
+	// at the end of the data when there is no message.
+	end := bytes.Index(data, []byte("\n\n")) + 1
+	if end <= 0 {
+		end = len(data)
+	}
+
+	payload := &bytes.Buffer{}
+	var signature []byte
+	inSignature := false
+	for _, line := range bytes.SplitAfter(data[:end], []byte("\n")) {
+		switch {
+		case bytes.HasPrefix(line, []byte("gpgsig ")):
+			inSignature = true
+			signature = append(signature, line[len("gpgsig "):]...)
+		case inSignature && bytes.HasPrefix(line, []byte(" ")):
+			signature = append(signature, line[1:]...)
+		default:
+			inSignature = false
+			payload.Write(line)
+		}
+	}
+	payload.Write(data[end:])
+
+	return payload.Bytes(), signature
+}
+
+// VerifyCommit verifies the content of a commit object.
+func VerifyCommit(data []byte, verifier Verifier) (*Verification, error) {
+	payload, signature := SplitCommit(data)
+	if signature == nil {
+		return &Verification{Status: Unsigned}, nil
+	}
+
+	return verifier.Verify(payload, signature)
+}
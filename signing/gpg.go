@@ -0,0 +1,135 @@
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// gpgSigner signs with a key from the local GnuPG keyring.
+type gpgSigner struct {
+	program string
+	key     string
+}
+
+// GPG returns a signer that uses the key with the given ID, or the
+// default key if the ID is empty, from the local GnuPG keyring.
+func GPG(key string) Signer {
+	return &gpgSigner{program: "gpg", key: key}
+}
+
+func (s *gpgSigner) Sign(payload []byte) ([]byte, error) {
+	args := []string{"--status-fd=2", "--batch", "--armor", "--detach-sign"}
+	if s.key != "" {
+		args = append(args, "--local-user", s.key)
+	}
+
+	cmd := exec.Command(s.program, args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	signature, err := cmd.Output()
+	if err != nil || !strings.Contains(stderr.String(), "[GNUPG:] SIG_CREATED ") {
+		return nil, fmt.Errorf("gpg failed to sign the data: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return signature, nil
+}
+
+type gpgVerifier struct {
+	program string
+}
+
+// GPGVerifier returns a verifier that checks OpenPGP signatures
+// against the local GnuPG keyring.
+func GPGVerifier() Verifier {
+	return &gpgVerifier{program: "gpg"}
+}
+
+func (v *gpgVerifier) Verify(payload, signature []byte) (*Verification, error) {
+	sigFile, err := writeTempFile("gitgone-gpg-signature-", signature)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(sigFile)
+
+	cmd := exec.Command(v.program, "--status-fd=1", "--batch", "--verify", sigFile, "-")
+	cmd.Stdin = bytes.NewReader(payload)
+
+	// gpg exits non-zero for bad signatures and unknown keys, which
+	// are reported in the status output.
+	output, _ := cmd.Output()
+
+	result := parseGPGStatus(output)
+	if result == nil {
+		return nil, fmt.Errorf("gpg did not report the signature status")
+	}
+
+	return result, nil
+}
+
+// gpgStatuses maps the gpg status keywords that report the outcome of
+// checking a signature to statuses. gpg reports exactly one of these
+// for each signature.
+var gpgStatuses = map[string]Status{
+	"GOODSIG":   Good,
+	"BADSIG":    Bad,
+	"EXPSIG":    Expired,
+	"EXPKEYSIG": Expired,
+	"REVKEYSIG": Revoked,
+}
+
+// parseGPGStatus interprets the machine readable output of
+// "gpg --status-fd", returning nil if there is no signature status.
+func parseGPGStatus(output []byte) *Verification {
+	var result *Verification
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimPrefix(scanner.Text(), "[GNUPG:] "), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "GOODSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG", "BADSIG":
+			result = &Verification{Status: gpgStatuses[fields[0]], Format: OpenPGP, KeyID: fields[1]}
+			if len(fields) == 3 {
+				result.Identity = fields[2]
+			}
+		case "ERRSIG", "NO_PUBKEY":
+			if result == nil {
+				result = &Verification{Status: UnknownKey, Format: OpenPGP, KeyID: fields[1]}
+			}
+		case "VALIDSIG":
+			if result != nil {
+				result.KeyID = fields[1]
+			}
+		}
+	}
+
+	return result
+}
+
+func writeTempFile(prefix string, data []byte) (string, error) {
+	file, err := ioutil.TempFile("", prefix)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
@@ -0,0 +1,73 @@
+package signing
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Tag describes an annotated tag object.
+type Tag struct {
+	Object     string
+	ObjectType string
+	Name       string
+	Tagger     string
+	Message    string
+}
+
+// Payload returns the content of the tag object without a
+// signature, which is the data that is signed.
+func (t *Tag) Payload() []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "object %s\n", t.Object)
+	fmt.Fprintf(buf, "type %s\n", t.ObjectType)
+	fmt.Fprintf(buf, "tag %s\n", t.Name)
+	fmt.Fprintf(buf, "tagger %s\n", t.Tagger)
+	buf.WriteString("\n")
+	buf.WriteString(t.Message)
+	if len(t.Message) > 0 && t.Message[len(t.Message)-1] != '\n' {
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes()
+}
+
+// SignTag returns the content of a signed tag object, in which the
+// signature follows the message.
+func SignTag(tag *Tag, signer Signer) ([]byte, error) {
+	payload := tag.Payload()
+
+	signature, err := signer.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(payload, signature...), nil
+}
+
+// SplitTag separates the content of a tag object into the signed
+// payload and the signature. The signature is nil for unsigned tags.
+func SplitTag(data []byte) ([]byte, []byte) {
+	idx := -1
+	for _, header := range signatureHeaders {
+		pos := bytes.LastIndex(data, append([]byte("\n"), header...))
+		if pos > idx {
+			idx = pos
+		}
+	}
+
+	if idx < 0 {
+		return data, nil
+	}
+
+	return data[:idx+1], data[idx+1:]
+}
+
+// VerifyTag verifies the content of a tag object.
+func VerifyTag(data []byte, verifier Verifier) (*Verification, error) {
+	payload, signature := SplitTag(data)
+	if signature == nil {
+		return &Verification{Status: Unsigned}, nil
+	}
+
+	return verifier.Verify(payload, signature)
+}
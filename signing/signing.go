@@ -0,0 +1,119 @@
+// Package signing provides pluggable signing and verification of
+// git objects. Signers produce detached, armored signatures that the
+// Repository implementations embed in tag and commit objects, in the
+// same format that git itself uses, so that objects signed by either
+// implementation verify with "git verify-tag" and "git verify-commit".
+package signing
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Format identifies the kind of a signature.
+type Format int
+
+const (
+	UnknownFormat Format = iota
+	OpenPGP
+	SSH
+	Testing
+)
+
+func (f Format) String() string {
+	switch f {
+	case OpenPGP:
+		return "openpgp"
+	case SSH:
+		return "ssh"
+	case Testing:
+		return "testing"
+	default:
+		return "unknown"
+	}
+}
+
+var signatureHeaders = map[Format][]byte{
+	OpenPGP: []byte("-----BEGIN PGP SIGNATURE-----"),
+	SSH:     []byte("-----BEGIN SSH SIGNATURE-----"),
+	Testing: []byte("-----BEGIN GITGONE TEST SIGNATURE-----"),
+}
+
+// DetectFormat returns the format of an armored signature.
+func DetectFormat(signature []byte) Format {
+	for format, header := range signatureHeaders {
+		if bytes.HasPrefix(bytes.TrimSpace(signature), header) {
+			return format
+		}
+	}
+
+	return UnknownFormat
+}
+
+// Signer produces an armored, detached signature of a payload.
+type Signer interface {
+	Sign([]byte) ([]byte, error)
+}
+
+// Status is the outcome of verifying a signature.
+type Status int
+
+const (
+	Unsigned Status = iota
+	Good
+	Bad
+	UnknownKey
+
+	// Expired and Revoked signatures are cryptographically valid,
+	// but were made by a key that has since expired or been
+	// revoked, or, for Expired, have passed their own expiry.
+	Expired
+	Revoked
+)
+
+func (s Status) String() string {
+	switch s {
+	case Unsigned:
+		return "unsigned"
+	case Good:
+		return "good"
+	case Bad:
+		return "bad"
+	case UnknownKey:
+		return "unknown-key"
+	case Expired:
+		return "expired"
+	case Revoked:
+		return "revoked"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// Verification describes the signature on an object. KeyID is the
+// key's fingerprint, and Identity is the user ID or principal
+// associated with the key, when the verifier knows it.
+type Verification struct {
+	Status   Status
+	Format   Format
+	KeyID    string
+	Identity string
+}
+
+// IsGood returns true if the object has a valid signature from a
+// known key.
+func (v *Verification) IsGood() bool {
+	return v.Status == Good
+}
+
+// Verifier checks a detached signature against a payload.
+type Verifier interface {
+	Verify(payload, signature []byte) (*Verification, error)
+}
+
+// FormatIdent formats an identity in the form used by the author,
+// committer and tagger lines of git objects.
+func FormatIdent(name, email string, when time.Time) string {
+	return fmt.Sprintf("%s <%s> %d %s", name, email, when.Unix(), when.Format("-0700"))
+}
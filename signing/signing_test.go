@@ -0,0 +1,184 @@
+package signing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SigningSuite struct {
+	dir            string
+	key            string
+	allowedSigners string
+}
+
+var _ = Suite(&SigningSuite{})
+
+func (s *SigningSuite) SetUpSuite(c *C) {
+	dir, err := ioutil.TempDir("", "gitgone-signing-")
+	c.Assert(err, IsNil)
+	s.dir = dir
+
+	s.key = filepath.Join(dir, "id_ed25519")
+	output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "",
+		"-C", "gitgone", "-f", s.key).CombinedOutput()
+	c.Assert(err, IsNil, Commentf("%s", output))
+
+	public, err := ioutil.ReadFile(s.key + ".pub")
+	c.Assert(err, IsNil)
+
+	s.allowedSigners = filepath.Join(dir, "allowed_signers")
+	c.Assert(ioutil.WriteFile(s.allowedSigners,
+		[]byte(fmt.Sprintf("gitgone@example.com %s", public)), 0644), IsNil)
+}
+
+func (s *SigningSuite) TearDownSuite(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *SigningSuite) TestSSHTagRoundTrip(c *C) {
+	tag := &Tag{
+		Object:     "0123456789012345678901234567890123456789",
+		ObjectType: "commit",
+		Name:       "v1.0",
+		Tagger:     FormatIdent("Gitgone", "gitgone@example.com", time.Unix(1500000000, 0).UTC()),
+		Message:    "release",
+	}
+	c.Assert(tag.Tagger, Equals, "Gitgone <gitgone@example.com> 1500000000 +0000")
+
+	data, err := SignTag(tag, SSHKey(s.key))
+	c.Assert(err, IsNil)
+
+	payload, signature := SplitTag(data)
+	c.Assert(string(payload), Equals, string(tag.Payload()))
+	c.Assert(DetectFormat(signature), Equals, SSH)
+
+	result, err := VerifyTag(data, NewVerifier(s.allowedSigners))
+	c.Assert(err, IsNil)
+	c.Assert(result.IsGood(), Equals, true)
+	c.Assert(result.Identity, Equals, "gitgone@example.com")
+
+	tampered := append([]byte("object 9876543210987654321098765432109876543210\n"), data[48:]...)
+	result, err = VerifyTag(tampered, NewVerifier(s.allowedSigners))
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, Bad)
+
+	result, err = VerifyTag(data, NewVerifier(""))
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, UnknownKey)
+
+	result, err = VerifyTag(tag.Payload(), NewVerifier(s.allowedSigners))
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, Unsigned)
+}
+
+func (s *SigningSuite) TestGPGStatusParsing(c *C) {
+	result := parseGPGStatus([]byte("[GNUPG:] NEWSIG\n" +
+		"[GNUPG:] GOODSIG 0123456789ABCDEF Gitgone <gitgone@example.com>\n" +
+		"[GNUPG:] VALIDSIG 0123456789ABCDEF0123456789ABCDEF01234567 2017-07-14 1500000000 0 4 0 1 10 00 0123456789ABCDEF0123456789ABCDEF01234567\n"))
+	c.Assert(result.Status, Equals, Good)
+	c.Assert(result.KeyID, Equals, "0123456789ABCDEF0123456789ABCDEF01234567")
+	c.Assert(result.Identity, Equals, "Gitgone <gitgone@example.com>")
+
+	result = parseGPGStatus([]byte("[GNUPG:] ERRSIG 0123456789ABCDEF 1 10 00 1500000000 9 -\n" +
+		"[GNUPG:] NO_PUBKEY 0123456789ABCDEF\n"))
+	c.Assert(result.Status, Equals, UnknownKey)
+	c.Assert(result.KeyID, Equals, "0123456789ABCDEF")
+
+	result = parseGPGStatus([]byte("[GNUPG:] BADSIG 0123456789ABCDEF Gitgone <gitgone@example.com>\n"))
+	c.Assert(result.Status, Equals, Bad)
+	c.Assert(result.IsGood(), Equals, false)
+
+	c.Assert(parseGPGStatus([]byte("[GNUPG:] NODATA 1\n")), IsNil)
+}
+
+func (s *SigningSuite) TestGPGStatusParsingExpiredAndRevoked(c *C) {
+	for line, status := range map[string]Status{
+		"GOODSIG":   Good,
+		"BADSIG":    Bad,
+		"EXPSIG":    Expired,
+		"EXPKEYSIG": Expired,
+		"REVKEYSIG": Revoked,
+	} {
+		result := parseGPGStatus([]byte("[GNUPG:] NEWSIG\n" +
+			"[GNUPG:] KEYEXPIRED 1500000000\n" +
+			"[GNUPG:] " + line + " 0123456789ABCDEF Gitgone <gitgone@example.com>\n"))
+		c.Assert(result, NotNil, Commentf(line))
+		c.Assert(result.Status, Equals, status, Commentf(line))
+		c.Assert(result.KeyID, Equals, "0123456789ABCDEF", Commentf(line))
+		c.Assert(result.Identity, Equals, "Gitgone <gitgone@example.com>", Commentf(line))
+		c.Assert(result.IsGood(), Equals, status == Good, Commentf(line))
+	}
+
+	c.Assert(Expired.String(), Equals, "expired")
+	c.Assert(Revoked.String(), Equals, "revoked")
+}
+
+func (s *SigningSuite) TestCommitRoundTrip(c *C) {
+	commit := &Commit{
+		Tree:      "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		Parents:   []string{"0123456789012345678901234567890123456789"},
+		Author:    "Gitgone <gitgone@example.com> 1500000000 +0000",
+		Committer: "Gitgone <gitgone@example.com> 1500000000 +0000",
+		Message:   "message\n\nbody\n",
+	}
+
+	data, err := SignCommit(commit, SSHKey(s.key))
+	c.Assert(err, IsNil)
+
+	payload, signature := SplitCommit(data)
+	c.Assert(string(payload), Equals, string(commit.Payload()))
+	c.Assert(DetectFormat(signature), Equals, SSH)
+
+	result, err := VerifyCommit(data, NewVerifier(s.allowedSigners))
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, Good)
+
+	signer := NewTestSigner("gitgone@example.com")
+	data, err = SignCommit(commit, signer)
+	c.Assert(err, IsNil)
+
+	result, err = VerifyCommit(data, signer)
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, Good)
+	c.Assert(result.Format, Equals, Testing)
+
+	// signatures in other formats are an error
+	_, err = signer.Verify(payload, signature)
+	c.Assert(err, NotNil)
+
+	_, signature = SplitCommit(data)
+	commit.Message = "changed\n"
+	result, err = signer.Verify(commit.Payload(), signature)
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, Bad)
+
+	result, err = VerifyCommit(commit.Payload(), signer)
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, Unsigned)
+}
+
+func (s *SigningSuite) TestSplitMalformedCommit(c *C) {
+	for _, data := range []string{
+		"",
+		"tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		"\n\nmessage",
+	} {
+		payload, signature := SplitCommit([]byte(data))
+		c.Assert(string(payload), Equals, data)
+		c.Assert(signature, IsNil)
+	}
+
+	// a signature in the last line, without a trailing newline
+	payload, signature := SplitCommit([]byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\ngpgsig sig\n continued"))
+	c.Assert(string(payload), Equals, "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n")
+	c.Assert(string(signature), Equals, "sig\ncontinued")
+}
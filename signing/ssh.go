@@ -0,0 +1,119 @@
+package signing
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// sshNamespace is the signature namespace git uses for objects.
+const sshNamespace = "git"
+
+type sshSigner struct {
+	keyFile string
+}
+
+// SSHKey returns a signer that signs with the private key in the
+// given file, or with the private key matching the public key in the
+// file via ssh-agent, as "git -c gpg.format=ssh" does.
+func SSHKey(keyFile string) Signer {
+	return &sshSigner{keyFile: keyFile}
+}
+
+func (s *sshSigner) Sign(payload []byte) ([]byte, error) {
+	cmd := exec.Command("ssh-keygen", "-Y", "sign", "-n", sshNamespace, "-f", s.keyFile)
+	cmd.Stdin = bytes.NewReader(payload)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	signature, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh-keygen failed to sign the data: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return signature, nil
+}
+
+type sshVerifier struct {
+	allowedSigners string
+}
+
+// SSHVerifier returns a verifier that checks SSH signatures against
+// an allowed signers file, in the format used by git's
+// gpg.ssh.allowedSignersFile setting.
+func SSHVerifier(allowedSigners string) Verifier {
+	return &sshVerifier{allowedSigners: allowedSigners}
+}
+
+var sshFingerprintPattern = regexp.MustCompile(`key (SHA256:\S+)`)
+
+func (v *sshVerifier) Verify(payload, signature []byte) (*Verification, error) {
+	result := &Verification{Status: UnknownKey, Format: SSH}
+	if v.allowedSigners == "" {
+		return result, nil
+	}
+
+	sigFile, err := writeTempFile("gitgone-ssh-signature-", signature)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(sigFile)
+
+	principals, err := exec.Command("ssh-keygen", "-Y", "find-principals",
+		"-f", v.allowedSigners, "-s", sigFile).Output()
+	if err != nil {
+		// the key is not in the allowed signers file.
+		return result, nil
+	}
+
+	for _, principal := range strings.Split(strings.TrimSpace(string(principals)), "\n") {
+		cmd := exec.Command("ssh-keygen", "-Y", "verify", "-f", v.allowedSigners,
+			"-I", principal, "-n", sshNamespace, "-s", sigFile)
+		cmd.Stdin = bytes.NewReader(payload)
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			result.Status = Bad
+			continue
+		}
+
+		result.Status = Good
+		result.Identity = principal
+		if match := sshFingerprintPattern.FindSubmatch(output); match != nil {
+			result.KeyID = string(match[1])
+		}
+		break
+	}
+
+	return result, nil
+}
+
+type formatVerifier struct {
+	verifiers map[Format]Verifier
+}
+
+// NewVerifier returns a verifier that checks OpenPGP signatures with
+// the local GnuPG keyring and SSH signatures with the allowed signers
+// file, which may be empty if SSH signatures cannot be verified.
+func NewVerifier(allowedSigners string) Verifier {
+	return &formatVerifier{
+		verifiers: map[Format]Verifier{
+			OpenPGP: GPGVerifier(),
+			SSH:     SSHVerifier(allowedSigners),
+		},
+	}
+}
+
+func (v *formatVerifier) Verify(payload, signature []byte) (*Verification, error) {
+	format := DetectFormat(signature)
+
+	verifier, ok := v.verifiers[format]
+	if !ok {
+		return nil, fmt.Errorf("cannot verify signatures in the %s format", format)
+	}
+
+	return verifier.Verify(payload, signature)
+}
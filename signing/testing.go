@@ -0,0 +1,59 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TestSigner signs and verifies objects without keys or external
+// programs, for use in tests. Its signatures are a hash of the
+// identity and the payload, and offer no security.
+type TestSigner struct {
+	Identity string
+}
+
+// NewTestSigner returns a signer that is also a verifier of its own
+// signatures.
+func NewTestSigner(identity string) *TestSigner {
+	return &TestSigner{Identity: identity}
+}
+
+func (s *TestSigner) digest(payload []byte) string {
+	sum := sha256.Sum256(append([]byte(s.Identity+"\x00"), payload...))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *TestSigner) keyID() string {
+	sum := sha256.Sum256([]byte(s.Identity))
+	return strings.ToUpper(hex.EncodeToString(sum[:8]))
+}
+
+func (s *TestSigner) Sign(payload []byte) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n-----END GITGONE TEST SIGNATURE-----\n",
+		signatureHeaders[Testing], s.Identity, s.digest(payload))), nil
+}
+
+func (s *TestSigner) Verify(payload, signature []byte) (*Verification, error) {
+	if DetectFormat(signature) != Testing {
+		return nil, fmt.Errorf("cannot verify signatures in the %s format", DetectFormat(signature))
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(signature), []byte("\n"))
+	if len(lines) != 4 {
+		return &Verification{Status: Bad, Format: Testing}, nil
+	}
+
+	if string(lines[1]) != s.Identity {
+		return &Verification{Status: UnknownKey, Format: Testing, Identity: string(lines[1])}, nil
+	}
+
+	result := &Verification{Status: Good, Format: Testing, KeyID: s.keyID(), Identity: s.Identity}
+	if string(lines[2]) != s.digest(payload) {
+		result.Status = Bad
+	}
+
+	return result, nil
+}